@@ -0,0 +1,40 @@
+package dependency_injection
+
+import "sync"
+
+// Ambient is a caller-managed token for one goroutine or task, such as a request, holding
+// the containers made current for it by RunScoped(...), innermost last. Go has no
+// goroutine-local storage, so code that resolves through Current() is handed the token
+// instead of the container. The zero value is ready to use.
+type Ambient struct {
+	stack []*DependencyInjection
+	mutex sync.Mutex
+}
+
+// RunScoped calls f with di as the current container of the token, as returned by
+// Current(), restoring the previous one when f returns or panics. Calls nest.
+func (a *Ambient) RunScoped(di *DependencyInjection, f func()) {
+	a.mutex.Lock()
+	var depth = len(a.stack)
+	a.stack = append(a.stack, di)
+	a.mutex.Unlock()
+
+	defer func() {
+		a.mutex.Lock()
+		a.stack = a.stack[:depth]
+		a.mutex.Unlock()
+	}()
+	f()
+}
+
+// Current returns the container made current for the token by the innermost
+// RunScoped(...) call, if any.
+func (a *Ambient) Current() (*DependencyInjection, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if len(a.stack) == 0 {
+		return nil, false
+	}
+	return a.stack[len(a.stack)-1], true
+}
@@ -0,0 +1,52 @@
+package dependency_injection
+
+import "testing"
+
+func TestAmbientNesting(t *testing.T) {
+	var ambient Ambient
+	outer, inner := NewDependencyInjection(), NewDependencyInjection()
+
+	if _, ok := ambient.Current(); ok {
+		t.Fatal("Current() outside RunScoped reported a container")
+	}
+	ambient.RunScoped(outer, func() {
+		if di, ok := ambient.Current(); !ok || di != outer {
+			t.Fatal("Current() is not the outer container")
+		}
+		ambient.RunScoped(inner, func() {
+			if di, ok := ambient.Current(); !ok || di != inner {
+				t.Fatal("Current() is not the inner container")
+			}
+		})
+		if di, ok := ambient.Current(); !ok || di != outer {
+			t.Fatal("outer container not restored after the inner RunScoped returned")
+		}
+	})
+	if _, ok := ambient.Current(); ok {
+		t.Fatal("container still current after RunScoped returned")
+	}
+}
+
+func TestAmbientRestoresAfterPanic(t *testing.T) {
+	var ambient Ambient
+	outer := NewDependencyInjection()
+
+	ambient.RunScoped(outer, func() {
+		func() {
+			defer func() { recover() }()
+			ambient.RunScoped(NewDependencyInjection(), func() { panic("handler failed") })
+		}()
+		if di, _ := ambient.Current(); di != outer {
+			t.Fatal("outer container not restored after the inner RunScoped panicked")
+		}
+	})
+}
+
+func TestAmbientTokensAreIndependent(t *testing.T) {
+	var one, two Ambient
+	one.RunScoped(NewDependencyInjection(), func() {
+		if _, ok := two.Current(); ok {
+			t.Fatal("container current for one token leaked into another")
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package dependency_injection
+
+import (
+	"strconv"
+	"testing"
+)
+
+type benchObject struct {
+	id int
+}
+
+// benchContainer returns a container holding n unrelated objects and one *testMemoryStore.
+func benchContainer(n int, scan bool) *DependencyInjection {
+	di := NewDependencyInjection()
+	di.EnableInterfaceScan(scan)
+	for i := 0; i < n; i++ {
+		di.Add(&benchObject{id: i})
+	}
+	AddAs[testStore](di, &testMemoryStore{})
+	return di
+}
+
+func BenchmarkResolve10kInterfaceScan(b *testing.B) {
+	di := benchContainer(10000, true)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MustAny[testStore](di)
+	}
+}
+
+// BenchmarkResolveIndexed resolves an interface implemented by the first of n concrete
+// registrations, which the interface index finds without scanning the others.
+func BenchmarkResolveIndexed(b *testing.B) {
+	for _, n := range []int{1000, 10000, 100000} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			di := NewDependencyInjection()
+			di.Add(&testMemoryStore{})
+			for i := 0; i < n; i++ {
+				di.Add(&benchObject{id: i})
+			}
+			MustAny[testStore](di)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				MustAny[testStore](di)
+			}
+		})
+	}
+}
+
+// BenchmarkAddIndexed adds dependencies to a container with an indexed interface,
+// which every Add keeps up to date.
+func BenchmarkAddIndexed(b *testing.B) {
+	di := NewDependencyInjection()
+	di.Add(&testMemoryStore{})
+	MustAny[testStore](di)
+	var deps = benchObjects(b.N)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for _, dep := range deps {
+		di.Add(dep)
+	}
+}
+
+func BenchmarkResolve10kNoInterfaceScan(b *testing.B) {
+	di := benchContainer(10000, false)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MustAny[testStore](di)
+	}
+}
+
+func BenchmarkAdd10kNoInterfaceScan(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchContainer(10000, false)
+	}
+}
+
+func BenchmarkAdd10kInterfaceScan(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchContainer(10000, true)
+	}
+}
+
+func BenchmarkMiss10kInterfaceScan(b *testing.B) {
+	di := benchContainer(10000, true)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		TryAny[*testConfig](di)
+	}
+}
+
+func BenchmarkMiss10kNoInterfaceScan(b *testing.B) {
+	di := benchContainer(10000, false)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		TryAny[*testConfig](di)
+	}
+}
+
+func BenchmarkKeyFor(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		keyFor[*testMemoryStore]()
+	}
+}
+
+func BenchmarkMustAny(b *testing.B) {
+	di := NewDependencyInjection()
+	di.Add(&testConfig{name: "app"})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MustAny[*testConfig](di)
+	}
+}
+
+func BenchmarkMustNeedCached(b *testing.B) {
+	di := NewDependencyInjection()
+	newer := func(*DependencyInjection) *testConfig { return &testConfig{name: "app"} }
+	MustNeed(di, newer)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MustNeed(di, newer)
+	}
+}
+
+// benchObjects returns n distinct objects to register.
+func benchObjects(n int) []interface{} {
+	var deps = make([]interface{}, n)
+	for i := range deps {
+		deps[i] = &benchObject{id: i}
+	}
+	return deps
+}
+
+func BenchmarkAddAll1000(b *testing.B) {
+	var deps = benchObjects(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewDependencyInjection().AddAll(deps...)
+	}
+}
+
+func BenchmarkAdd1000(b *testing.B) {
+	var deps = benchObjects(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		di := NewDependencyInjection()
+		for _, dep := range deps {
+			di.Add(dep)
+		}
+	}
+}
+
+func BenchmarkAddAll1000Contended(b *testing.B) {
+	var deps = benchObjects(1000)
+	di := NewDependencyInjection()
+	di.Add(&testConfig{})
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			di.AddAll(deps...)
+			MustAny[*testConfig](di)
+		}
+	})
+}
+
+func BenchmarkAdd1000Contended(b *testing.B) {
+	var deps = benchObjects(1000)
+	di := NewDependencyInjection()
+	di.Add(&testConfig{})
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			for _, dep := range deps {
+				di.Add(dep)
+			}
+			MustAny[*testConfig](di)
+		}
+	})
+}
+
+// benchResolveParallel resolves an interface from many goroutines at once.
+func benchResolveParallel(b *testing.B, freeze bool) {
+	di := NewDependencyInjection()
+	for i := 0; i < 100; i++ {
+		di.Add(&benchObject{id: i})
+	}
+	di.Add(&testMemoryStore{})
+	if freeze {
+		di.Freeze()
+	}
+	MustAny[testStore](di)
+	b.SetParallelism(64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			MustAny[testStore](di)
+		}
+	})
+}
+
+func BenchmarkResolveParallelLocked(b *testing.B) {
+	benchResolveParallel(b, false)
+}
+
+func BenchmarkResolveParallelFrozen(b *testing.B) {
+	benchResolveParallel(b, true)
+}
@@ -0,0 +1,133 @@
+package dependency_injection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrInvalidConstructor is returned by Build(...) when the constructor is not a function
+// returning T, optionally followed by an error.
+var ErrInvalidConstructor = errors.New("constructor must be a function returning T and optionally an error")
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Build calls constructor with each of its parameters resolved from the container and
+// returns its result. Parameters of type *DependencyInjection receive the container itself,
+// and slice parameters receive every dependency of their element type.
+// If any parameter cannot be resolved, the constructor is not called and the returned
+// error names every missing type. A result that is Initializable is initialized, and
+// an error of its Init is returned. The result is not registered within the container.
+func Build[T any](di *DependencyInjection, constructor interface{}) (result T, err error) {
+	if result, err = build[T](di, constructor); err != nil {
+		return result, err
+	}
+	if err = initialize(di, result); err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
+
+// build is Build without the initialization of the result.
+func build[T any](di *DependencyInjection, constructor interface{}) (result T, err error) {
+	fn, err := constructorOf[T](constructor)
+	if err != nil {
+		return result, err
+	}
+	var ft = fn.Type()
+
+	var args = make([]reflect.Value, ft.NumIn())
+	var errs []error
+	for i := range args {
+		arg, err := argument(di, ft.In(i))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		args[i] = arg
+	}
+	if len(errs) > 0 {
+		return result, errors.Join(errs...)
+	}
+
+	var out = fn.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return result, out[1].Interface().(error)
+	}
+	result, _ = out[0].Interface().(T)
+	return result, nil
+}
+
+// AddConstructor registers a plain constructor, as accepted by Build(...), as the Singleton
+// factory of type T. Its parameters are resolved on the first resolution of T, and unlike
+// those of AddFactory(...) they are known to Validate(). A failing constructor panics.
+func AddConstructor[T any](di *DependencyInjection, constructor interface{}) error {
+	fn, err := constructorOf[T](constructor)
+	if err != nil {
+		return err
+	}
+	var ft = fn.Type()
+
+	var params = make([]reflect.Type, ft.NumIn())
+	for i := range params {
+		params[i] = ft.In(i)
+	}
+	var f = newFactory(Singleton, func(di *DependencyInjection) T {
+		result, err := build[T](di, constructor)
+		if err != nil {
+			panic(err)
+		}
+		return result
+	})
+	f.params = params
+
+	di.info.mutex.Lock()
+
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		return nil
+	}
+
+	di.info.setFactory(keyFor[T](), f)
+
+	di.info.mutex.Unlock()
+	return nil
+}
+
+// constructorOf checks that constructor is a function returning T, optionally followed
+// by an error.
+func constructorOf[T any](constructor interface{}) (reflect.Value, error) {
+	var fn = reflect.ValueOf(constructor)
+	if fn.Kind() != reflect.Func || fn.IsNil() {
+		return fn, ErrInvalidConstructor
+	}
+	var ft = fn.Type()
+	if ft.NumOut() < 1 || ft.NumOut() > 2 || !ft.Out(0).AssignableTo(keyFor[T]()) ||
+		(ft.NumOut() == 2 && ft.Out(1) != errorType) || ft.IsVariadic() {
+		return fn, fmt.Errorf("%w: got %s", ErrInvalidConstructor, ft)
+	}
+	return fn, nil
+}
+
+// argument resolves a constructor parameter of type t from the container. A slice
+// parameter that is not registered as such receives every dependency of its element
+// type, as All(...) would return them, and is empty if there are none.
+func argument(di *DependencyInjection, t reflect.Type) (reflect.Value, error) {
+	if t == reflect.TypeOf(di) {
+		return reflect.ValueOf(di), nil
+	}
+	dep, ok := di.resolve(t, isType(t))
+	if ok {
+		return reflect.ValueOf(dep), nil
+	}
+	if t.Kind() == reflect.Slice {
+		var deps = di.all(t.Elem(), isType(t.Elem()))
+		var slice = reflect.MakeSlice(t, len(deps), len(deps))
+		for i, dep := range deps {
+			slice.Index(i).Set(reflect.ValueOf(dep))
+		}
+		return slice, nil
+	}
+	return reflect.Value{}, di.notFound(t)
+}
@@ -0,0 +1,86 @@
+package dependency_injection
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testHandler interface {
+	Handle() string
+}
+
+type testRoute struct {
+	path string
+}
+
+func (r *testRoute) Handle() string { return r.path }
+
+type testRouter struct {
+	handlers []testHandler
+	config   *testConfig
+}
+
+func newTestRouter(handlers []testHandler, config *testConfig) *testRouter {
+	return &testRouter{handlers: handlers, config: config}
+}
+
+func TestBuildSliceParameter(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&testRoute{path: "/a"})
+	di.Add(&testRoute{path: "/b"})
+	di.Add(&testRoute{path: "/c"})
+	di.Add(&testConfig{})
+
+	router, err := Build[*testRouter](di, newTestRouter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(router.handlers) != 3 {
+		t.Fatalf("constructor received %d handlers, want 3", len(router.handlers))
+	}
+}
+
+func TestBuildEmptySliceParameter(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&testConfig{})
+
+	router, err := Build[*testRouter](di, newTestRouter)
+	if err != nil || len(router.handlers) != 0 {
+		t.Fatalf("Build without handlers = %v, %v; want an empty slice", router, err)
+	}
+}
+
+type validateA struct{ b *validateB }
+type validateB struct{ a *validateA }
+
+func TestValidateMissing(t *testing.T) {
+	di := NewDependencyInjection()
+	AddConstructor[*testRouter](di, newTestRouter)
+
+	err := di.Validate()
+	if !errors.Is(err, ErrDependencyNotFound) || !strings.Contains(err.Error(), "testConfig") {
+		t.Fatalf("Validate = %v, want ErrDependencyNotFound naming *testConfig", err)
+	}
+	di.Add(&testConfig{})
+	if err := di.Validate(); err != nil {
+		t.Fatalf("Validate with every parameter resolvable = %v", err)
+	}
+}
+
+func TestValidateCycle(t *testing.T) {
+	di := NewDependencyInjection()
+	AddConstructor[*validateA](di, func(b *validateB) *validateA { return &validateA{b: b} })
+	AddConstructor[*validateB](di, func(a *validateA) *validateB { return &validateB{a: a} })
+
+	if err := di.Validate(); !errors.Is(err, ErrCircularDependency) {
+		t.Fatalf("Validate = %v, want ErrCircularDependency", err)
+	}
+}
+
+func TestAddConstructorInvalid(t *testing.T) {
+	di := NewDependencyInjection()
+	if err := AddConstructor[*testRouter](di, func() *testConfig { return nil }); !errors.Is(err, ErrInvalidConstructor) {
+		t.Fatalf("AddConstructor with the wrong result = %v, want ErrInvalidConstructor", err)
+	}
+}
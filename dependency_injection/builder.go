@@ -0,0 +1,62 @@
+package dependency_injection
+
+// Builder wires a container step by step with chainable methods, so that a bootstrap
+// reads top to bottom. Each step registers within the container at once, exactly as the
+// method of DependencyInjection it is named after; Build returns the container.
+// Factories, whose type parameters methods cannot have, are added with BuilderFactory(...).
+type Builder struct {
+	di *DependencyInjection
+}
+
+// NewBuilder returns a Builder wiring a new container configured by the given options.
+func NewBuilder(opts ...Option) *Builder {
+	return &Builder{di: NewDependencyInjection(opts...)}
+}
+
+// Add registers a dependency as with DependencyInjection.Add.
+func (b *Builder) Add(dep interface{}) *Builder {
+	b.di.Add(dep)
+	return b
+}
+
+// AddAll registers dependencies as with DependencyInjection.AddAll.
+func (b *Builder) AddAll(deps ...interface{}) *Builder {
+	b.di.AddAll(deps...)
+	return b
+}
+
+// AddNamed registers a dependency under a name as with DependencyInjection.AddNamed.
+func (b *Builder) AddNamed(name string, dep interface{}) *Builder {
+	b.di.AddNamed(name, dep)
+	return b
+}
+
+// AddToGroup registers a dependency in a group as with DependencyInjection.AddToGroup.
+func (b *Builder) AddToGroup(group string, dep interface{}) *Builder {
+	b.di.AddToGroup(group, dep)
+	return b
+}
+
+// AddWithCleanup registers a dependency with its cleanup as with DependencyInjection.AddWithCleanup.
+func (b *Builder) AddWithCleanup(dep interface{}, cleanup func() error) *Builder {
+	b.di.AddWithCleanup(dep, cleanup)
+	return b
+}
+
+// Build returns the container wired by the builder.
+func (b *Builder) Build() *DependencyInjection {
+	return b.di
+}
+
+// BuilderFactory registers a factory for T as with AddFactory(...) and returns the builder.
+func BuilderFactory[T any](b *Builder, newer func(di *DependencyInjection) T) *Builder {
+	AddFactory(b.di, newer)
+	return b
+}
+
+// BuilderAs registers a dependency under the type key of T as with AddAs(...) and
+// returns the builder.
+func BuilderAs[T any](b *Builder, dep T) *Builder {
+	AddAs[T](b.di, dep)
+	return b
+}
@@ -0,0 +1,39 @@
+package dependency_injection
+
+import "testing"
+
+func TestBuilderChain(t *testing.T) {
+	var cleaned bool
+	b := NewBuilder(WithMaxDepth(10)).
+		Add(&testConfig{name: "app"}).
+		AddAll(&testEnglish{accent: "uk"}, &lifetimeCounter{n: 3}).
+		AddNamed("db", "postgres://").
+		AddToGroup("handlers", &pooledConn{id: 1}).
+		AddWithCleanup(&benchObject{id: 7}, func() error { cleaned = true; return nil })
+	b = BuilderFactory(b, func(di *DependencyInjection) *testPerson {
+		return &testPerson{name: MustAny[*testConfig](di).name}
+	})
+	di := BuilderAs[testStore](b, &testMemoryStore{}).Build()
+
+	if got := MustAny[*testConfig](di); got.name != "app" {
+		t.Fatalf("Add: got %v", got)
+	}
+	if got := MustAny[*lifetimeCounter](di); got.n != 3 {
+		t.Fatalf("AddAll: got %v", got)
+	}
+	if got := MustNamed[string](di, "db"); got != "postgres://" {
+		t.Fatalf("AddNamed: got %q", got)
+	}
+	if got := GroupOfType[*pooledConn](di, "handlers"); len(got) != 1 || got[0].id != 1 {
+		t.Fatalf("AddToGroup: got %v", got)
+	}
+	if got := MustAny[*testPerson](di); got.name != "app" {
+		t.Fatalf("BuilderFactory: got %v", got)
+	}
+	if _, ok := TryAny[testStore](di); !ok {
+		t.Fatal("BuilderAs: testStore not found")
+	}
+	if err := di.Dispose(); err != nil || !cleaned {
+		t.Fatalf("AddWithCleanup: Dispose = %v, cleaned %v", err, cleaned)
+	}
+}
@@ -0,0 +1,126 @@
+package dependency_injection
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+type contextKey struct{}
+
+// ContextWithDI returns a copy of ctx carrying the given DependencyInjection.
+func ContextWithDI(ctx context.Context, di *DependencyInjection) context.Context {
+	return context.WithValue(ctx, contextKey{}, di)
+}
+
+// FromContext returns the DependencyInjection carried by ctx, if any.
+func FromContext(ctx context.Context) (*DependencyInjection, bool) {
+	di, ok := ctx.Value(contextKey{}).(*DependencyInjection)
+	return di, ok && di != nil
+}
+
+// NewScopeFromContext creates a DependencyInjection for injection using the Scoped
+// lifetime, which is disposed once ctx is done. Errors returned by Dispose() are discarded.
+func NewScopeFromContext(ctx context.Context, parent *DependencyInjection) *DependencyInjection {
+	child := NewScopedDependencyInjection(parent)
+	go func() {
+		<-ctx.Done()
+		_ = child.Dispose()
+	}()
+	return child
+}
+
+// AnyContext retrieves a dependency of type T like Any(...), but stops waiting once ctx is
+// done while a factory is still constructing it, returning ctx.Err(). The factory itself
+// keeps running, and its result is cached for later resolutions as usual. A dependency
+// that is already registered, or missing without a factory, is reported right away, even
+// if ctx is done. The resolution
+// runs through the middlewares installed with Use(...), like that of Any(...).
+// Factories registered with AddContextFactory(...) receive ctx, and one failing with an
+// error matching ctx.Err() once ctx is done makes AnyContext return that error.
+func AnyContext[T any](ctx context.Context, di *DependencyInjection) (result T, err error) {
+	var t0 = keyFor[T]()
+
+	var carrier = &resolution{parent: di.path, done: 1, ctx: ctx}
+	if di.path != nil {
+		carrier.depth = di.path.depth
+	}
+	di = di.along(carrier)
+
+	dep, ok := di.resolveThrough(t0, is[T], func(t0 reflect.Type, match func(dep interface{}) bool) (interface{}, bool) {
+		if dep, ok, lazy := di.ready(t0, match); ok || !lazy {
+			return dep, ok
+		}
+
+		type outcome struct {
+			dep   interface{}
+			ok    bool
+			panic interface{}
+		}
+		var done = make(chan outcome, 1)
+		go func() {
+			var o outcome
+			defer func() {
+				o.panic = recover()
+				done <- o
+			}()
+			o.dep, o.ok = di.resolveStep(t0, match)
+		}()
+
+		select {
+		case o := <-done:
+			if e, ok := (o.panic).(error); ok && ctx.Err() != nil && errors.Is(e, ctx.Err()) {
+				err = e
+				return nil, false
+			}
+			if o.panic != nil {
+				panic(o.panic)
+			}
+			return o.dep, o.ok
+		case <-ctx.Done():
+			err = ctx.Err()
+			return nil, false
+		}
+	})
+	if err != nil {
+		return result, err
+	}
+	di.resolved(t0, ok)
+	if !ok {
+		return result, di.notFound(t0)
+	}
+	return (dep).(T), nil
+}
+
+// AddContextFactory registers a Singleton factory for the dependency of type T, like
+// AddFactory(...), that receives the context of the resolution: that of AnyContext(...),
+// so it can pass its deadline on to the I/O it performs, or context.Background() for
+// other resolutions. A failing factory panics, and runs again on the next resolution.
+func AddContextFactory[T any](di *DependencyInjection, newer func(ctx context.Context, di *DependencyInjection) (T, error)) {
+	addFactory(di, Singleton, func(di *DependencyInjection) T {
+		result, err := newer(di.path.contextOf(), di)
+		if err != nil {
+			panic(err)
+		}
+		return result
+	})
+}
+
+// ready resolves a dependency of type key t0 like resolveStep, unless that would invoke
+// a factory or fall back to federated containers, in which case it reports the resolution as lazy.
+func (di *DependencyInjection) ready(t0 reflect.Type, match func(dep interface{}) bool) (dep interface{}, ok, lazy bool) {
+	var resolving = di
+	var visited visitedSet
+	for ; di != nil && visited.add(di.info); di = di.Parent() {
+		if dep, ok := di.find(t0, match); ok {
+			return decorate(resolving, t0, dep), true, false
+		} else if dep == ambiguity {
+			return nil, false, false
+		}
+		if di.factoryOf(t0) != nil {
+			return nil, false, true
+		}
+		lazy = lazy || len(di.federation()) > 0
+	}
+	return nil, false, lazy
+}
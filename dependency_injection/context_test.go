@@ -0,0 +1,143 @@
+package dependency_injection
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type contextCloser struct {
+	closed chan struct{}
+}
+
+func (c *contextCloser) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func TestNewScopeFromContextDisposesOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	scope := NewScopeFromContext(ctx, NewDependencyInjection())
+
+	closer := &contextCloser{closed: make(chan struct{})}
+	scope.Add(closer)
+
+	select {
+	case <-closer.closed:
+		t.Fatal("Close called before the context was cancelled")
+	case <-time.After(10 * time.Millisecond):
+	}
+	cancel()
+	select {
+	case <-closer.closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close not called after the context was cancelled")
+	}
+}
+
+func TestNewScopeFromContextIsScoped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	parent := NewDependencyInjection()
+	scope := NewScopeFromContext(ctx, parent)
+
+	if scope.Lifetime() != Scoped || scope.Parent() != parent {
+		t.Fatal("NewScopeFromContext did not return a Scoped child of the parent")
+	}
+}
+
+func TestContextWithDIRoundTrip(t *testing.T) {
+	di := NewDependencyInjection()
+	ctx := ContextWithDI(context.Background(), di)
+
+	if got, ok := FromContext(ctx); !ok || got != di {
+		t.Fatalf("FromContext = %p, %v; want the stored container", got, ok)
+	}
+}
+
+func TestFromContextBare(t *testing.T) {
+	if got, ok := FromContext(context.Background()); ok || got != nil {
+		t.Fatalf("FromContext on a bare context = %p, %v; want nil, false", got, ok)
+	}
+	if _, ok := FromContext(ContextWithDI(context.Background(), nil)); ok {
+		t.Fatal("FromContext reported a nil container")
+	}
+}
+
+func TestAnyContextMissingAfterDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := AnyContext[*testEnglish](ctx, NewDependencyInjection()); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("AnyContext for a type without factory = %v, want ErrDependencyNotFound", err)
+	}
+}
+
+func TestAnyContextTimeoutOnSlowFactory(t *testing.T) {
+	di := NewDependencyInjection()
+	release := make(chan struct{})
+	AddFactory(di, func(*DependencyInjection) *testConfig {
+		<-release
+		return &testConfig{name: "slow"}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := AnyContext[*testConfig](ctx, di); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("AnyContext on a slow factory = %v, want context.DeadlineExceeded", err)
+	}
+
+	close(release)
+	got, err := AnyContext[*testConfig](context.Background(), di)
+	if err != nil || got.name != "slow" {
+		t.Fatalf("AnyContext once the factory finished = %v, %v", got, err)
+	}
+}
+
+func TestAnyContextRegisteredAfterDone(t *testing.T) {
+	di := NewDependencyInjection()
+	config := &testConfig{}
+	di.Add(config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got, err := AnyContext[*testConfig](ctx, di); err != nil || got != config {
+		t.Fatalf("AnyContext with a done context = %v, %v; want the registered value", got, err)
+	}
+}
+
+func TestAddContextFactoryReceivesContext(t *testing.T) {
+	var started = make(chan struct{})
+	var observed = make(chan error, 1)
+	var dial = func(di *DependencyInjection) {
+		AddContextFactory(di, func(ctx context.Context, di *DependencyInjection) (*testConfig, error) {
+			if ctx.Done() == nil {
+				return &testConfig{name: "dialed"}, nil
+			}
+			close(started)
+			<-ctx.Done()
+			observed <- ctx.Err()
+			return nil, ctx.Err()
+		})
+	}
+
+	di := NewDependencyInjection()
+	dial(di)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	if _, err := AnyContext[*testConfig](ctx, di); !errors.Is(err, context.Canceled) {
+		t.Fatalf("AnyContext cancelled during construction = %v, want context.Canceled", err)
+	}
+	if err := <-observed; !errors.Is(err, context.Canceled) {
+		t.Fatalf("factory observed %v, want the cancellation of the AnyContext context", err)
+	}
+
+	di = NewDependencyInjection()
+	dial(di)
+	if got := MustAny[*testConfig](di); got.name != "dialed" {
+		t.Fatalf("Any through a context factory = %v, want it made with context.Background()", got)
+	}
+}
@@ -0,0 +1,35 @@
+package dependency_injection
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// debugLog writes the debug lines of a container, one at a time.
+type debugLog struct {
+	w     io.Writer
+	mutex sync.Mutex
+}
+
+// SetDebugWriter sets the writer to which the container writes a line for each dependency
+// it registers, e.g. with Add or by caching a factory object, each one it removes, and each
+// lookup by Any(...) with the type key and whether it was found. A nil writer, the default,
+// turns it off. Registrations and removals are written while the container is locked, so
+// w must not use the container. Child containers created afterwards inherit the writer.
+func (di *DependencyInjection) SetDebugWriter(w io.Writer) {
+	if w == nil {
+		di.info.debug.Store(nil)
+		return
+	}
+	di.info.debug.Store(&debugLog{w: w})
+}
+
+// debugf writes a line to the debug writer of the container, if it has one.
+func (info *dependencyInjection) debugf(format string, args ...interface{}) {
+	if d := info.debug.Load(); d != nil {
+		d.mutex.Lock()
+		fmt.Fprintf(d.w, format+"\n", args...)
+		d.mutex.Unlock()
+	}
+}
@@ -0,0 +1,40 @@
+package dependency_injection
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDebugWriterLogsSequence(t *testing.T) {
+	di := NewDependencyInjection()
+	var buf bytes.Buffer
+	di.SetDebugWriter(&buf)
+
+	config := &testConfig{name: "app"}
+	di.Add(config)
+	MustAny[*testConfig](di)
+	di.Remove(config)
+	di.Remove(config)
+	var missing *testConfig
+	_ = Any(di, &missing)
+	AddAs[testStore](di, &testMemoryStore{})
+
+	want := []string{
+		"add *dependency_injection.testConfig as *dependency_injection.testConfig",
+		"resolve *dependency_injection.testConfig hit",
+		"remove *dependency_injection.testConfig",
+		"resolve *dependency_injection.testConfig miss",
+		"add *dependency_injection.testMemoryStore as dependency_injection.testStore",
+	}
+	if got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("debug lines:\n%s\nwant:\n%s", buf.String(), strings.Join(want, "\n"))
+	}
+
+	buf.Reset()
+	di.SetDebugWriter(nil)
+	di.Add(config)
+	if buf.Len() != 0 {
+		t.Fatalf("debug writer still written after SetDebugWriter(nil): %q", buf.String())
+	}
+}
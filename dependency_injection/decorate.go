@@ -0,0 +1,51 @@
+package dependency_injection
+
+import "reflect"
+
+// Decorate registers a decorator that wraps every dependency of type T resolved
+// through the container or the containers below it. Decorators compose in
+// registration order, the first registered wrapping the original dependency, and
+// decorators of a parent container wrap before those of its children. They run on
+// each resolution and receive the original dependency, which can still be called.
+func Decorate[T any](di *DependencyInjection, decorator func(inner T) T) {
+	di.info.mutex.Lock()
+
+	var t0 = keyFor[T]()
+
+	if di.info.decorators == nil {
+		di.info.decorators = make(map[reflect.Type][]func(interface{}) interface{})
+	}
+	di.info.decorators[t0] = append(di.info.decorators[t0], func(inner interface{}) interface{} {
+		return decorator(inner.(T))
+	})
+	di.info.publish()
+
+	di.info.mutex.Unlock()
+}
+
+// decorate applies the decorators registered for type key t0 in di and its parents to dep.
+func decorate(di *DependencyInjection, t0 reflect.Type, dep interface{}) interface{} {
+	var chain [][]func(interface{}) interface{}
+
+	var visited visitedSet
+	for ; di != nil && visited.add(di.info); di = di.Parent() {
+		if v := di.info.view.Load(); v != nil {
+			if decorators := v.decorators[t0]; len(decorators) > 0 {
+				chain = append(chain, decorators)
+			}
+			continue
+		}
+		di.info.mutex.RLock()
+		if decorators := di.info.decorators[t0]; len(decorators) > 0 {
+			chain = append(chain, decorators)
+		}
+		di.info.mutex.RUnlock()
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, decorator := range chain[i] {
+			dep = decorator(dep)
+		}
+	}
+	return dep
+}
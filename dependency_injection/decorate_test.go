@@ -0,0 +1,55 @@
+package dependency_injection
+
+import "testing"
+
+type decorated struct {
+	inner testGreeter
+	word  string
+}
+
+func (d *decorated) Greet() string { return d.inner.Greet() + " " + d.word }
+
+func TestDecorateStacked(t *testing.T) {
+	di := NewDependencyInjection()
+	AddAs[testGreeter](di, &testEnglish{})
+	Decorate(di, func(inner testGreeter) testGreeter { return &decorated{inner: inner, word: "there"} })
+	Decorate(di, func(inner testGreeter) testGreeter { return &decorated{inner: inner, word: "friend"} })
+
+	if got := MustAny[testGreeter](di).Greet(); got != "hello there friend" {
+		t.Fatalf("Greet = %q, want both decorators applied in registration order", got)
+	}
+}
+
+func TestDecorateKeepsOriginal(t *testing.T) {
+	di := NewDependencyInjection()
+	english := &testEnglish{}
+	AddAs[testGreeter](di, english)
+
+	var innerSeen testGreeter
+	Decorate(di, func(inner testGreeter) testGreeter {
+		innerSeen = inner
+		return &decorated{inner: inner, word: "there"}
+	})
+	MustAny[testGreeter](di)
+	if innerSeen != english {
+		t.Fatal("decorator did not receive the original dependency")
+	}
+	if !di.ContainsInstance(english) {
+		t.Fatal("Decorate unregistered the original dependency")
+	}
+}
+
+func TestDecorateParentBeforeChild(t *testing.T) {
+	parent := NewDependencyInjection()
+	AddAs[testGreeter](parent, &testEnglish{})
+	Decorate(parent, func(inner testGreeter) testGreeter { return &decorated{inner: inner, word: "parent"} })
+	child := NewChild(parent)
+	Decorate(child, func(inner testGreeter) testGreeter { return &decorated{inner: inner, word: "child"} })
+
+	if got := MustAny[testGreeter](child).Greet(); got != "hello parent child" {
+		t.Fatalf("Greet from the child = %q, want the parent's decorator inside the child's", got)
+	}
+	if got := MustAny[testGreeter](parent).Greet(); got != "hello parent" {
+		t.Fatalf("Greet from the parent = %q, want only its own decorator", got)
+	}
+}
@@ -3,36 +3,123 @@ package dependency_injection
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"unsafe"
 )
 
 // ErrDependencyNotFound is returned by Any(...) when no corresponding dependency is found.
 var ErrDependencyNotFound = errors.New("dependency not found")
 
+// ErrFrozen is the panic value of registrations within a container after Freeze().
+var ErrFrozen = errors.New("container is frozen")
+
+// ErrNotComparable is the panic value of registering a value that cannot be told apart
+// from its copies, such as a slice: one that is not comparable, nor a function or map.
+var ErrNotComparable = errors.New("dependency is not comparable")
+
+// DependencyNotFoundError is returned by Any(...) and Build(...) naming the type of the
+// dependency that was not found. It matches ErrDependencyNotFound with errors.Is.
+type DependencyNotFoundError struct {
+	Type string
+}
+
+func (e *DependencyNotFoundError) Error() string {
+	return ErrDependencyNotFound.Error() + ": " + e.Type
+}
+
+func (e *DependencyNotFoundError) Unwrap() error {
+	return ErrDependencyNotFound
+}
+
+// ErrTypeMismatch is matched by the error of Any(...) when no dependency of the requested
+// interface is found but one resembling it is registered, see TypeMismatchError.
+var ErrTypeMismatch = errors.New("registered dependency does not satisfy the requested type")
+
+// TypeMismatchError is returned by Any(...) and Build(...) when no dependency of the
+// requested interface is found, naming a registered dependency that has some of its
+// methods but does not implement it, e.g. after the interface changed. It matches both
+// ErrDependencyNotFound and ErrTypeMismatch with errors.Is.
+type TypeMismatchError struct {
+	Type   string
+	Found  string
+	Reason string
+}
+
+func (e *TypeMismatchError) Error() string {
+	return ErrDependencyNotFound.Error() + ": " + e.Type + ": " + e.Found +
+		" is registered but does not implement it: " + e.Reason
+}
+
+func (e *TypeMismatchError) Unwrap() []error {
+	return []error{ErrDependencyNotFound, ErrTypeMismatch}
+}
+
+// globalKey is the type key of the global bucket, which holds every dependency.
+var globalKey reflect.Type
+
 type dependencyInjection struct {
-	dependencies map[string]map[interface{}]struct{}
-	transient bool
-	mutex sync.RWMutex
+	dependencies    map[reflect.Type]map[interface{}]struct{}
+	order           map[reflect.Type][]interface{}
+	named           map[string]interface{}
+	keyed           map[interface{}]interface{}
+	groups          map[string][]interface{}
+	factories       map[reflect.Type]*factory
+	instances       map[*factory]*made
+	decorators      map[reflect.Type][]func(interface{}) interface{}
+	pool            *pool
+	cleanups        map[interface{}][]func() error
+	noInterfaceScan bool
+	maxDepth        int
+	flights         map[interface{}]*flight
+	children        []*dependencyInjection
+	hooks           []func(typeName string, found bool)
+	middlewares     []func(next ResolveFunc) ResolveFunc
+	federated       []*DependencyInjection
+	hits, misses    atomic.Uint64
+	implementers    map[reflect.Type][]interface{}
+	parent          *DependencyInjection
+	shared          bool
+	transient       bool
+	frozen          bool
+	strict          bool
+	rejectZero      bool
+	multi           MultiPolicy
+	view            atomic.Pointer[view]
+	debug           atomic.Pointer[debugLog]
+	mutex           sync.RWMutex
 }
 
 // DependencyInjection acts as a container for managing dependencies.
 type DependencyInjection struct {
-	info *dependencyInjection
+	info     *dependencyInjection
+	lifetime Lifetime
+	path     *resolution
 }
 
-// NewDependencyInjection initializes and returns a new instance of DependencyInjection.
-func NewDependencyInjection() (di *DependencyInjection) {
+// NewDependencyInjection initializes and returns a new instance of DependencyInjection,
+// configured by the given options in order.
+func NewDependencyInjection(opts ...Option) (di *DependencyInjection) {
 	di = &DependencyInjection{info: &dependencyInjection{}}
 
-	data := make(map[string]map[interface{}]struct{})
-	
+	data := make(map[reflect.Type]map[interface{}]struct{})
+
 	di.info.dependencies = data
+	di.info.order = make(map[reflect.Type][]interface{})
 
+	for _, opt := range opts {
+		opt(di)
+	}
 	return
 }
 
-// IsTransient returns whether container is transient, creating new instances for each MustNeed request
+// IsTransient returns whether container is transient, creating new instances for each MustNeed request.
 func (di *DependencyInjection) IsTransient() bool {
 	di.info.mutex.RLock()
 	t := di.info.transient
@@ -40,70 +127,677 @@ func (di *DependencyInjection) IsTransient() bool {
 	return t
 }
 
-// SetTransient sets whether container is transient, creating new instances for each MustNeed request
+// SetTransient sets whether container is transient, creating new instances for each MustNeed request.
+// A transient container no longer accepts registrations.
 func (di *DependencyInjection) SetTransient(t bool) {
 	di.info.mutex.Lock()
 	di.info.transient = t
 	di.info.mutex.Unlock()
 }
 
-// Add registers a dependency within the container.
+// Freeze makes the container read-only: registering or removing dependencies afterwards,
+// e.g. with Add, Remove or Replace, panics with ErrFrozen. Dependencies made by factories
+// and MustNeed are still cached, and child containers remain mutable. It cannot be undone.
+// Resolution from a frozen container reads a copy of its registrations without locking.
+func (di *DependencyInjection) Freeze() {
+	di.info.mutex.Lock()
+	di.info.frozen = true
+	di.info.publish()
+	di.info.mutex.Unlock()
+}
+
+// writable reports whether the container accepts registrations, which transient containers
+// ignore. It must be called with the write lock held; a frozen container releases the
+// lock and panics with ErrFrozen.
+func (info *dependencyInjection) writable() bool {
+	if info.frozen {
+		info.mutex.Unlock()
+		panic(ErrFrozen)
+	}
+	return !info.transient
+}
+
+// EnableInterfaceScan sets whether dependencies are also kept in a global bucket that
+// resolution scans when nothing is registered under the exact type key, which is how
+// interfaces resolve to concrete types added with Add. It is enabled by default.
+// Disabling it saves memory and scan time for containers that only resolve types
+// registered under their exact key, e.g. with AddAs.
+func (di *DependencyInjection) EnableInterfaceScan(enable bool) {
+	di.info.mutex.Lock()
+
+	if enable && di.info.noInterfaceScan {
+		for _, dep := range di.info.registered() {
+			di.info.insert(globalKey, dep)
+		}
+	} else if !enable {
+		delete(di.info.dependencies, globalKey)
+		delete(di.info.order, globalKey)
+		di.info.implementers = nil
+	}
+	di.info.noInterfaceScan = !enable
+	di.info.publish()
+
+	di.info.mutex.Unlock()
+}
+
+// Add registers a dependency within the container under its dynamic type, even
+// when dep is held in an interface variable. Adding an already registered
+// dependency again moves it to the end of the registration order. Adding nil does nothing.
+// Adding a value that cannot be told apart from its copies, such as a slice, panics
+// with ErrNotComparable; functions and maps are known by the pointer they hold.
+// Adding the container to itself panics with ErrSelfRegistration, while other
+// containers, such as a parent, can be added like any other dependency.
 func (di *DependencyInjection) Add(dep interface{}) {
+	di.addAs(typeKey(dep), dep)
+}
+
+// AddAll registers each of the dependencies like Add, in order, under a single write lock.
+func (di *DependencyInjection) AddAll(deps ...interface{}) {
+	for _, dep := range deps {
+		checkIdentity(dep)
+	}
+
 	di.info.mutex.Lock()
 
-	if di.info.transient {
+	if !di.info.writable() {
 		di.info.mutex.Unlock()
 		return
 	}
 
-	var t0 = "*" + reflect.TypeOf(dep).String()
-	const t1 = ""
+	di.info.admit(deps...)
+	for _, dep := range deps {
+		di.info.register(typeKey(dep), dep)
+	}
 
-	if di.info.dependencies[t0] == nil {
-		di.info.dependencies[t0] = make(map[interface{}]struct{})
+	di.info.mutex.Unlock()
+}
+
+// AddEach registers each element of slice, a slice or array, like AddAll, so that
+// a pre-built list such as a []Handler is resolved by All[Handler] and Any[Handler]
+// rather than registered as a whole under the slice type. A nil slice adds nothing;
+// anything other than a slice or array panics.
+func (di *DependencyInjection) AddEach(slice interface{}) {
+	if slice == nil {
+		return
 	}
-	di.info.dependencies[t0][dep] = struct{}{}
+	var v = reflect.ValueOf(slice)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		panic(fmt.Errorf("AddEach of %v, want a slice or array", v.Type()))
+	}
+
+	var deps = make([]interface{}, v.Len())
+	for i := range deps {
+		deps[i] = v.Index(i).Interface()
+	}
+	di.AddAll(deps...)
+}
+
+// AddAs registers a dependency within the container under the type key of the
+// interface I, so resolving I finds it directly rather than by scanning all dependencies.
+func AddAs[I any](di *DependencyInjection, dep I) {
+	AddTyped(di, dep)
+}
+
+// AddTyped registers a dependency within the container under its static type T,
+// regardless of the dynamic type of dep.
+func AddTyped[T any](di *DependencyInjection, dep T) {
+	di.addAs(keyFor[T](), dep)
+}
+
+// addAs registers a dependency within the container under the type key t0.
+func (di *DependencyInjection) addAs(t0 reflect.Type, dep interface{}) {
+	checkIdentity(dep)
+
+	di.info.mutex.Lock()
+
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		return
+	}
+
+	di.info.admit(dep)
+	di.info.register(t0, dep)
+
+	di.info.mutex.Unlock()
+}
+
+// cache registers a dependency made by the container itself under the type key t0.
+// Unlike registrations by the user, it is allowed within a frozen container, and a
+// dependency without an identity is left uncached instead of panicking.
+func (di *DependencyInjection) cache(t0 reflect.Type, dep interface{}) {
+	if !identifiable(typeKey(dep)) {
+		return
+	}
+
+	di.info.mutex.Lock()
 
-	if di.info.dependencies[t1] == nil {
-		di.info.dependencies[t1] = make(map[interface{}]struct{})
+	if !di.info.transient {
+		di.info.register(t0, dep)
+		di.info.publish()
 	}
-	di.info.dependencies[t1][dep] = struct{}{}
 
 	di.info.mutex.Unlock()
 }
 
-// Remove unregisters a dependency from the container.
+// Remove unregisters a dependency from the container, deleting the instance from
+// every bucket it appears in.
 func (di *DependencyInjection) Remove(dep interface{}) {
 	di.info.mutex.Lock()
 
-	if di.info.transient {
+	if !di.info.writable() || dep == nil {
 		di.info.mutex.Unlock()
 		return
 	}
 
-	var t0 = "*" + reflect.TypeOf(dep).String()
-	const t1 = ""
+	di.info.unregister(dep)
+
+	di.info.mutex.Unlock()
+}
+
+// RemoveType unregisters every dependency registered under the type key of T, deleting
+// those instances from every bucket they appear in. Factories of T and the parent
+// container are left untouched.
+func RemoveType[T any](di *DependencyInjection) {
+	di.info.mutex.Lock()
+
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		return
+	}
+
+	var t0 = keyFor[T]()
+
+	for _, dep := range append([]interface{}(nil), di.info.order[t0]...) {
+		di.info.unregister(dep)
+	}
+
+	di.info.mutex.Unlock()
+}
+
+// ContainsInstance reports whether the exact dep instance is registered within the container.
+func (di *DependencyInjection) ContainsInstance(dep interface{}) bool {
+	di.info.mutex.RLock()
+
+	var t0 = typeKey(dep)
+	var t1 = globalKey
+
+	_, ok0 := di.info.dependencies[t0][identity(dep)]
+	_, ok1 := di.info.dependencies[t1][identity(dep)]
+
+	di.info.mutex.RUnlock()
+	return ok0 || ok1
+}
+
+// Len returns the number of distinct type keys registered within the container.
+func (di *DependencyInjection) Len() int {
+	di.info.mutex.RLock()
+
+	var n = len(di.info.dependencies)
+	if _, ok := di.info.dependencies[globalKey]; ok {
+		n--
+	}
+
+	di.info.mutex.RUnlock()
+	return n
+}
+
+// Keys returns a sorted snapshot of the type keys registered within the container,
+// named with their full package paths, e.g. "*github.com/acme/app/storage.Repo[github.com/acme/app/model.User]".
+func (di *DependencyInjection) Keys() []string {
+	di.info.mutex.RLock()
+
+	var keys = make([]string, 0, len(di.info.dependencies))
+	for t := range di.info.dependencies {
+		if t != globalKey {
+			keys = append(keys, typeName(t))
+		}
+	}
+
+	di.info.mutex.RUnlock()
+	sort.Strings(keys)
+	return keys
+}
+
+// Range calls f for each dependency registered within the container with its type key,
+// ordered by key and then by registration, until f returns false. It iterates over
+// a snapshot, so f may add or remove dependencies without deadlocking.
+func (di *DependencyInjection) Range(f func(key string, dep interface{}) bool) {
+	type entry struct {
+		key string
+		dep interface{}
+	}
+	di.info.mutex.RLock()
+
+	var entries []entry
+	for t, deps := range di.info.order {
+		if t != globalKey {
+			for _, dep := range deps {
+				entries = append(entries, entry{typeName(t), dep})
+			}
+		}
+	}
+
+	di.info.mutex.RUnlock()
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	for _, e := range entries {
+		if !f(e.key, e.dep) {
+			return
+		}
+	}
+}
+
+// Replace unregisters every dependency of type T and registers dep in its place,
+// under a single write lock, so concurrent resolutions never observe a missing or duplicate T.
+func Replace[T any](di *DependencyInjection, dep T) {
+	checkIdentity(dep)
+
+	di.info.mutex.Lock()
+
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		return
+	}
+
+	var t0 = typeKey(dep)
+
+	di.info.admit(dep)
+	for _, old := range di.info.registered() {
+		if _, ok := (old).(T); ok {
+			di.info.unregister(old)
+		}
+	}
+	di.info.register(t0, dep)
+
+	di.info.mutex.Unlock()
+}
+
+// AddOrKeep registers dep within the container like Add, unless a dependency of type T
+// or a factory for T is already registered within the container, and reports whether
+// dep was added. The check and the registration happen under a single write lock, so
+// of concurrent callers exactly one adds its dependency. It suits defaults that the user
+// may have registered an override for.
+func AddOrKeep[T any](di *DependencyInjection, dep T) bool {
+	checkIdentity(dep)
+
+	di.info.mutex.Lock()
+
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		return false
+	}
+
+	var t0 = keyFor[T]()
+	if di.info.factories[t0] != nil {
+		di.info.mutex.Unlock()
+		return false
+	}
+	for _, deps := range [][]interface{}{di.info.order[t0], di.info.registered()} {
+		for _, old := range deps {
+			if is[T](old) {
+				di.info.mutex.Unlock()
+				return false
+			}
+		}
+	}
+	di.info.admit(dep)
+	if interface{}(dep) == nil {
+		di.info.mutex.Unlock()
+		return false
+	}
+	di.info.register(typeKey(dep), dep)
+
+	di.info.mutex.Unlock()
+	return true
+}
+
+// typeKey returns the type key under which dep is registered, its dynamic type.
+func typeKey(dep interface{}) reflect.Type {
+	return reflect.TypeOf(dep)
+}
+
+// keyFor returns the type key under which dependencies of type T are resolved.
+func keyFor[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// typeName returns the name of type t qualified with the full package path of every
+// named type it is made of, so that same-named types of different packages, including
+// instantiations of generic types, never share a name.
+func typeName(t reflect.Type) string {
+	if t.Name() != "" && t.PkgPath() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + typeName(t.Elem())
+	case reflect.Array:
+		return "[" + strconv.Itoa(t.Len()) + "]" + typeName(t.Elem())
+	case reflect.Map:
+		return "map[" + typeName(t.Key()) + "]" + typeName(t.Elem())
+	case reflect.Chan:
+		switch t.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + typeName(t.Elem())
+		case reflect.SendDir:
+			return "chan<- " + typeName(t.Elem())
+		}
+		if t.Elem().Kind() == reflect.Chan && t.Elem().ChanDir() == reflect.RecvDir {
+			return "chan (" + typeName(t.Elem()) + ")"
+		}
+		return "chan " + typeName(t.Elem())
+	case reflect.Func:
+		return funcName(t)
+	case reflect.Struct:
+		return structName(t)
+	}
+	return t.String()
+}
+
+// funcName returns the name of the function type t like typeName, naming its parameters,
+// the variadic one included, and its results with typeName.
+func funcName(t reflect.Type) string {
+	var b strings.Builder
+	b.WriteString("func(")
+	for i := 0; i < t.NumIn(); i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			b.WriteString("..." + typeName(t.In(i).Elem()))
+		} else {
+			b.WriteString(typeName(t.In(i)))
+		}
+	}
+	b.WriteString(")")
+	if t.NumOut() == 1 {
+		b.WriteString(" " + typeName(t.Out(0)))
+	} else if t.NumOut() > 1 {
+		b.WriteString(" (")
+		for i := 0; i < t.NumOut(); i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(typeName(t.Out(i)))
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+// structName returns the name of the unnamed struct type t like typeName, naming the type
+// of each of its fields with typeName.
+func structName(t reflect.Type) string {
+	if t.NumField() == 0 {
+		return "struct {}"
+	}
+	var b strings.Builder
+	b.WriteString("struct {")
+	for i := 0; i < t.NumField(); i++ {
+		if i > 0 {
+			b.WriteString(";")
+		}
+		var field = t.Field(i)
+		b.WriteString(" ")
+		if !field.Anonymous {
+			b.WriteString(field.Name + " ")
+		}
+		b.WriteString(typeName(field.Type))
+		if field.Tag != "" {
+			b.WriteString(" " + strconv.Quote(string(field.Tag)))
+		}
+	}
+	b.WriteString(" }")
+	return b.String()
+}
+
+// unhashable identifies a function or map, whose type cannot be used as a map key,
+// by its type and the pointer it is made of, which copies share.
+type unhashable struct {
+	t reflect.Type
+	p unsafe.Pointer
+}
+
+// identity returns the key that dep is known by within the container: dep itself, or
+// for functions and maps an unhashable key, so registering the same value again is
+// recognized and distinct closures are not. Other values that are not comparable, such
+// as slices, are refused by checkIdentity; their key has no pointer and matches nothing.
+func identity(dep interface{}) interface{} {
+	if t := reflect.TypeOf(dep); t != nil && !t.Comparable() {
+		switch t.Kind() {
+		case reflect.Map:
+			return unhashable{t: t, p: reflect.ValueOf(dep).UnsafePointer()}
+		case reflect.Func:
+			return unhashable{t: t, p: closureOf(dep)}
+		}
+		return unhashable{t: t}
+	}
+	return dep
+}
+
+// closureOf returns the pointer that the function dep is made of. This is deliberately
+// unsafe: reflect only exposes the code pointer of a function, which every closure of a
+// function literal shares, so keying by it would take a closure for another and drop it.
+// A func value is a single pointer to its closure in the gc and gccgo toolchains alike;
+// it is read from a typed copy of dep rather than from the layout of the interface.
+func closureOf(dep interface{}) unsafe.Pointer {
+	var v = reflect.New(reflect.TypeOf(dep))
+	v.Elem().Set(reflect.ValueOf(dep))
+	return *(*unsafe.Pointer)(v.UnsafePointer())
+}
+
+// identifiable reports whether values of type t can be told apart from their copies:
+// comparable values by equality, functions and maps by the pointer they are made of.
+func identifiable(t reflect.Type) bool {
+	return t == nil || t.Comparable() || t.Kind() == reflect.Func || t.Kind() == reflect.Map
+}
+
+// checkIdentity panics with ErrNotComparable if dep cannot be registered, see identifiable.
+func checkIdentity(dep interface{}) {
+	if t := typeKey(dep); !identifiable(t) {
+		panic(fmt.Errorf("%w: %s, register a pointer to it instead", ErrNotComparable, t))
+	}
+}
+
+// Clear unregisters all dependencies from the container. The link to the parent
+// container is kept, and the parent container itself is left untouched.
+func (di *DependencyInjection) Clear() {
+	di.info.mutex.Lock()
 
-	delete(di.info.dependencies[t0], dep)
-	delete(di.info.dependencies[t1], dep)
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		return
+	}
+
+	di.info.dependencies = make(map[reflect.Type]map[interface{}]struct{})
+	di.info.order = make(map[reflect.Type][]interface{})
+	di.info.named = nil
+	di.info.keyed = nil
+	di.info.groups = nil
+	di.info.factories = nil
+	di.info.instances = nil
+	di.info.decorators = nil
+	di.info.cleanups = nil
+	di.info.implementers = nil
 
 	di.info.mutex.Unlock()
 }
 
+// register inserts dep into the bucket for type key t and, unless the interface scan is
+// disabled, into the global bucket. A nil dep is ignored, as it has no type to be found by.
+func (info *dependencyInjection) register(t reflect.Type, dep interface{}) {
+	if dep == nil {
+		return
+	}
+	if info.debug.Load() != nil {
+		info.debugf("add %v as %v", typeKey(dep), t)
+	}
+	info.insert(t, dep)
+	if !info.noInterfaceScan {
+		info.insert(globalKey, dep)
+	}
+}
+
+// registered returns every dependency registered within the container. The global bucket
+// keeps the registration order; without it, dependencies are grouped by sorted type key.
+func (info *dependencyInjection) registered() []interface{} {
+	if !info.noInterfaceScan {
+		return info.order[globalKey]
+	}
+	var keys = make([]reflect.Type, 0, len(info.order))
+	for t := range info.order {
+		keys = append(keys, t)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return typeName(keys[i]) < typeName(keys[j])
+	})
+
+	var deps []interface{}
+	var seen = make(map[interface{}]struct{})
+	for _, t := range keys {
+		for _, dep := range info.order[t] {
+			if _, dup := seen[identity(dep)]; !dup {
+				seen[identity(dep)] = struct{}{}
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return deps
+}
+
+// insert appends dep to the bucket for type key t, keeping the registration order.
+func (info *dependencyInjection) insert(t reflect.Type, dep interface{}) {
+	info.erase(t, dep)
+
+	if info.dependencies[t] == nil {
+		info.dependencies[t] = make(map[interface{}]struct{})
+	}
+	info.dependencies[t][identity(dep)] = struct{}{}
+	info.order[t] = append(info.order[t], dep)
+	if t == globalKey {
+		info.indexDep(dep)
+	}
+}
+
+// unregister removes dep from every bucket it appears in and forgets its cleanups.
+func (info *dependencyInjection) unregister(dep interface{}) {
+	var removed bool
+	for t := range info.dependencies {
+		if info.erase(t, dep) {
+			removed = true
+		}
+	}
+	delete(info.cleanups, identity(dep))
+	if removed && info.debug.Load() != nil {
+		info.debugf("remove %v", typeKey(dep))
+	}
+}
+
+// erase removes dep from the bucket for type key t, dropping the bucket once empty,
+// and reports whether dep was in the bucket.
+func (info *dependencyInjection) erase(t reflect.Type, dep interface{}) bool {
+	var id = identity(dep)
+	if _, ok := info.dependencies[t][id]; !ok {
+		return false
+	}
+	delete(info.dependencies[t], id)
+	if t == globalKey {
+		info.unindexDep(id)
+	}
+
+	var order = info.order[t]
+	for i := range order {
+		if identity(order[i]) == id {
+			info.order[t] = append(order[:i:i], order[i+1:]...)
+			break
+		}
+	}
+
+	if len(info.dependencies[t]) == 0 {
+		delete(info.dependencies, t)
+		delete(info.order, t)
+	}
+	return true
+}
+
 // MustNeed injects a dependency of type T using the given constructor function and
-// panics if the injection is unsuccessful.
+// panics if the injection is unsuccessful, including when constructors depend on
+// each other in a cycle. Scoped containers only reuse objects
+// cached within the scope itself, never those of the parent. Transient containers
+// construct a new object on every call and neither resolve nor cache it.
+// Concurrent calls for the same missing type run the constructor once and share the object;
+// constructors on different goroutines waiting for each other's objects panic with the cycle.
 func MustNeed[T any](di *DependencyInjection, newer func(di *DependencyInjection) *T) (result T) {
-	err := Any[T](di, &result)
+	if di.IsTransient() {
+		return construct(di, newer)
+	}
+	var t0 = keyFor[T]()
+
+	var ok bool
+	if result, ok = cached[T](di, t0); ok {
+		return
+	}
+	var owner = di.owner()
+	dep, err := owner.once(t0, t0, func(flying *DependencyInjection) (interface{}, error) {
+		var di = di.along(flying.path)
+		if result, ok := cached[T](di, t0); ok {
+			return result, nil
+		}
+		result := construct(di, newer)
+		owner.cache(typeKey(result), result)
+		return result, nil
+	})
 	if err != nil {
-		result = *newer(di)
-		di.Add(result)
-	} else if di.IsTransient() {
-		return *newer(di)
+		panic(err)
+	}
+	result, _ = (dep).(T)
+	return
+}
+
+// Need injects a dependency of type T using the given constructor function, like
+// MustNeed, but returns the error of a failing constructor instead of panicking.
+// The result is only cached when the constructor succeeds.
+func Need[T any](di *DependencyInjection, newer func(di *DependencyInjection) (*T, error)) (result T, err error) {
+	if di.IsTransient() {
+		return constructErr(di, newer)
+	}
+	var t0 = keyFor[T]()
+
+	var ok bool
+	if result, ok = cached[T](di, t0); ok {
+		return
 	}
+	var owner = di.owner()
+	dep, err := owner.once(t0, t0, func(flying *DependencyInjection) (interface{}, error) {
+		var di = di.along(flying.path)
+		if result, ok := cached[T](di, t0); ok {
+			return result, nil
+		}
+		result, err := constructErr(di, newer)
+		if err == nil {
+			owner.cache(typeKey(result), result)
+		}
+		return result, err
+	})
+	result, _ = (dep).(T)
 	return
 }
 
-// MustAny retrieves and returns a dependency of type T, panicking if the retrieval fails.
+// cached returns the dependency of type T that MustNeed and Need reuse: the one
+// registered within a Scoped container itself, or the one resolved by other containers.
+func cached[T any](di *DependencyInjection, t0 reflect.Type) (T, bool) {
+	if di.Lifetime() == Scoped {
+		return find[T](di, t0)
+	}
+	return lookup[T](di)
+}
+
+// MustAny retrieves and returns a dependency of type T, panicking if the retrieval fails
+// with the message of the error of Any(...), which names T, e.g. "dependency not found: *app.Service".
 func MustAny[T any](di *DependencyInjection) (result T) {
 	err := Any(di, &result)
 	if err != nil {
@@ -114,37 +808,369 @@ func MustAny[T any](di *DependencyInjection) (result T) {
 
 // Any assigns a dependency of type T to the provided res pointer.
 func Any[T any](di *DependencyInjection, res *T) error {
-	if di == nil {
-		return ErrDependencyNotFound
+	result, ok := lookup[T](di)
+	di.resolved(keyFor[T](), ok)
+	if !ok {
+		return di.notFound(keyFor[T]())
 	}
-	di.info.mutex.RLock()
+	*res = result
+	return nil
+}
+
+// AnyFrom retrieves a dependency of type T like Any(...), also returning the level of the
+// container that provided it: 0 for the container itself, 1 for its parent, etc., which
+// tells a scoped override from a dependency shared by the root. A dependency that a
+// middleware installed with Use(...) supplies without resolving it is reported at level 0.
+func AnyFrom[T any](di *DependencyInjection) (result T, level int, err error) {
+	var t0 = keyFor[T]()
 
-	var t0 = reflect.TypeOf(res).String()
-	const t1 = ""
+	dep, ok := di.resolveThrough(t0, is[T], func(t0 reflect.Type, match func(dep interface{}) bool) (dep interface{}, ok bool) {
+		dep, level, ok = di.resolveLevel(t0, match)
+		return
+	})
+	di.resolved(t0, ok)
+	if !ok {
+		return result, 0, di.notFound(t0)
+	}
+	return (dep).(T), level, nil
+}
 
-	var deps0 = di.info.dependencies[t0]
-	for dep := range deps0 {
-		result, ok := (dep).(T)
-		if ok {
-			*res = result
-			di.info.mutex.RUnlock()
-			return nil
+// AnyFunc resolves a dependency of type T like Any(...) and calls use with it, returning
+// the error of use. If T is not found, use is not called and the error of Any is returned,
+// which matches ErrDependencyNotFound.
+func AnyFunc[T any](di *DependencyInjection, use func(T) error) error {
+	var result T
+	if err := Any(di, &result); err != nil {
+		return err
+	}
+	return use(result)
+}
+
+// Lazy returns a function resolving the dependency of type T like Any(...) on its first
+// successful call and returning the same dependency on later calls, so a constructor can
+// take the function and resolve T only when it is needed, e.g. to break a cycle between
+// two services. A failed resolution is retried by the next call. It is safe for concurrent use.
+func Lazy[T any](di *DependencyInjection) func() (T, error) {
+	var result T
+	var resolved bool
+	var mutex sync.Mutex
+	return func() (T, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if !resolved {
+			var dep T
+			if err := Any(di, &dep); err != nil {
+				return dep, err
+			}
+			result, resolved = dep, true
 		}
+		return result, nil
+	}
+}
+
+// TryAny retrieves a dependency of type T, reporting whether it was found instead of returning an error.
+func TryAny[T any](di *DependencyInjection) (T, bool) {
+	return lookup[T](di)
+}
+
+// AnyOr retrieves a dependency of type T, returning def if it is not found or di is nil.
+// The default is not registered within the container.
+func AnyOr[T any](di *DependencyInjection, def T) T {
+	if result, ok := lookup[T](di); ok {
+		return result
 	}
-	var deps1 = di.info.dependencies[t1]
-	for dep := range deps1 {
-		result, ok := (dep).(T)
-		if ok {
-			*res = result
-			di.info.mutex.RUnlock()
-			return nil
+	return def
+}
+
+// Has reports whether a dependency of type T is registered in the container, its parents
+// or the containers they federate, see Federate(...).
+// A registered factory counts as a match but is not invoked. Has does not resolve T, so
+// the middlewares installed with Use(...) are not run and cannot hide it.
+func Has[T any](di *DependencyInjection) bool {
+	var t0 = keyFor[T]()
+
+	return !di.walk(func(c *DependencyInjection, _ int, _ bool) bool {
+		_, ok := find[T](c, t0)
+		return !ok && c.factoryOf(t0) == nil
+	})
+}
+
+// lookup resolves a dependency of type T, falling back to the parent containers on a miss.
+func lookup[T any](di *DependencyInjection) (result T, ok bool) {
+	var dep interface{}
+	if dep, ok = di.resolve(keyFor[T](), is[T]); ok {
+		result = (dep).(T)
+	}
+	return
+}
+
+// resolve resolves a dependency registered under type key t0 or satisfying match through
+// the resolution middlewares of the container and its parents, see Use(...).
+func (di *DependencyInjection) resolve(t0 reflect.Type, match func(dep interface{}) bool) (dep interface{}, ok bool) {
+	return di.resolveThrough(t0, match, di.resolveStep)
+}
+
+// resolveThrough resolves a dependency of type key t0 like resolve, with step in place
+// of resolveStep as the innermost step for t0.
+func (di *DependencyInjection) resolveThrough(t0 reflect.Type, match func(dep interface{}) bool,
+	step func(t0 reflect.Type, match func(dep interface{}) bool) (interface{}, bool)) (dep interface{}, ok bool) {
+	var middlewares = di.middlewares()
+	if len(middlewares) == 0 {
+		return step(t0, match)
+	}
+
+	var next ResolveFunc = func(t reflect.Type) (interface{}, bool) {
+		if t == t0 {
+			return step(t0, match)
+		}
+		return di.resolveStep(t, isType(t))
+	}
+	for _, mw := range middlewares {
+		next = mw(next)
+	}
+	if dep, ok = next(t0); ok && !match(dep) {
+		return nil, false
+	}
+	return
+}
+
+// resolveStep resolves a dependency registered under type key t0 or satisfying match,
+// falling back to the parent containers on a miss, and applies its decorators.
+func (di *DependencyInjection) resolveStep(t0 reflect.Type, match func(dep interface{}) bool) (dep interface{}, ok bool) {
+	dep, _, ok = di.resolveLevel(t0, match)
+	return
+}
+
+// resolveLevel resolves a dependency like resolveStep, also returning the level of the
+// container providing it: 0 for the container itself, 1 for its parent, etc.
+func (di *DependencyInjection) resolveLevel(t0 reflect.Type, match func(dep interface{}) bool) (dep interface{}, level int, ok bool) {
+	var visited visitedSet
+	return di.resolveVisiting(t0, match, &visited)
+}
+
+// resolveVisiting resolves a dependency like resolveLevel, skipping the containers in
+// visited and adding those it tries, so that federated containers are tried once each.
+// A miss in the container and those of its parents not visited before falls back to the
+// containers they federate, in order; a dependency found there is reported at the level
+// of the container federating the one providing it.
+func (di *DependencyInjection) resolveVisiting(t0 reflect.Type, match func(dep interface{}) bool,
+	visited *visitedSet) (dep interface{}, level int, ok bool) {
+	var resolving = di
+	for ; di != nil && visited.add(di.info); di = di.Parent() {
+		if dep, ok = di.find(t0, match); ok || dep == ambiguity {
+			break
+		}
+		if f := di.factoryOf(t0); f != nil {
+			dep = produce(di, resolving, f, t0)
+			ok = match(dep)
+			break
 		}
+		level++
+	}
+	if !ok && dep != ambiguity {
+		dep, level, ok = resolving.federate(level, t0, match, visited)
+	}
+	if ok {
+		dep = decorate(resolving, t0, dep)
+	}
+	return
+}
+
+// find resolves a dependency of type T registered directly in the container.
+func find[T any](di *DependencyInjection, t0 reflect.Type) (result T, ok bool) {
+	var dep interface{}
+	if dep, ok = di.find(t0, is[T]); ok {
+		result = (dep).(T)
+	}
+	return
+}
+
+// find resolves a dependency satisfying match registered directly in the container.
+// When several dependencies match, the one chosen by the MultiPolicy wins, by default the
+// most recently added one: first among those registered under the exact type key t0,
+// then among all registered dependencies.
+func (di *DependencyInjection) find(t0 reflect.Type, match func(dep interface{}) bool) (interface{}, bool) {
+	if v := di.info.view.Load(); v != nil {
+		if dep, ok, done := v.find(t0, match); done {
+			return dep, ok
+		}
+	}
+
+	di.info.mutex.RLock()
+
+	if di.info.rejectZero {
+		var accept = match
+		match = func(dep interface{}) bool {
+			return accept(dep) && !zero(dep)
+		}
+	}
+
+	var deps1 []interface{}
+	if !di.info.noInterfaceScan && t0.Kind() == reflect.Interface {
+		deps1 = di.implementersOf(t0)
+	} else if !di.info.noInterfaceScan {
+		deps1 = di.info.order[globalKey]
 	}
+	dep, ok := pick(di.info.multi, match, di.info.order[t0], deps1)
+
 	di.info.mutex.RUnlock()
-	if t0 != "**dependency_injection.DependencyInjection" && (interface{}(di) != interface{}(*res)) && Any[*DependencyInjection](di, &di) == nil {
-		return Any[T](di, res)
+	return dep, ok
+}
+
+// is reports whether dep is a T.
+func is[T any](dep interface{}) bool {
+	_, ok := (dep).(T)
+	return ok
+}
+
+// isType returns a match function reporting whether a dependency is of type t,
+// the reflection equivalent of is.
+func isType(t reflect.Type) func(dep interface{}) bool {
+	return func(dep interface{}) bool {
+		var dt = reflect.TypeOf(dep)
+		if dt == nil {
+			return false
+		}
+		if t.Kind() == reflect.Interface {
+			return dt.Implements(t)
+		}
+		return dt == t
 	}
-	return ErrDependencyNotFound
+}
+
+// All retrieves every distinct dependency of type T from the container and its parents.
+// Dependencies of each container are returned in registration order, those registered
+// under the exact type key first, followed by the dependencies of its parent, and then
+// by those of the containers they federate, see Federate(...).
+func All[T any](di *DependencyInjection) (results []T) {
+	for _, dep := range di.all(keyFor[T](), is[T]) {
+		results = append(results, (dep).(T))
+	}
+	return
+}
+
+// AnyAll appends every distinct dependency of type T from the container and its parents,
+// as returned by All(...), to the slice out points to, keeping its existing elements.
+// It returns an ErrDependencyNotFound error if no dependency matched.
+func AnyAll[T any](di *DependencyInjection, out *[]T) error {
+	var results = All[T](di)
+	if len(results) == 0 {
+		return &DependencyNotFoundError{Type: keyFor[T]().String()}
+	}
+	*out = append(*out, results...)
+	return nil
+}
+
+// all retrieves every distinct dependency registered under type key t0 or satisfying
+// match from the container and its parents, in the order documented by All.
+func (di *DependencyInjection) all(t0 reflect.Type, match func(dep interface{}) bool) (results []interface{}) {
+	var t1 = globalKey
+
+	seen := make(map[interface{}]struct{})
+	di.walk(func(c *DependencyInjection, _ int, _ bool) bool {
+		c.info.mutex.RLock()
+		for _, t := range [...]reflect.Type{t0, t1} {
+			for _, dep := range c.info.order[t] {
+				if _, dup := seen[identity(dep)]; dup {
+					continue
+				}
+				if match(dep) {
+					seen[identity(dep)] = struct{}{}
+					results = append(results, dep)
+				}
+			}
+		}
+		c.info.mutex.RUnlock()
+		return true
+	})
+	return
+}
+
+// Parent returns the container that resolutions fall back to, or nil if there is none.
+// It is set when the container is created and never changes, so it is read without locking.
+func (di *DependencyInjection) Parent() *DependencyInjection {
+	return di.info.parent
+}
+
+// Root returns the topmost container of the parent chain, which is di itself if it has no parent.
+func (di *DependencyInjection) Root() *DependencyInjection {
+	var visited visitedSet
+	for visited.add(di.info) && di.Parent() != nil {
+		di = di.Parent()
+	}
+	return di
+}
+
+// NewChild creates an empty DependencyInjection whose resolutions fall back to parent.
+// Dependencies added to the child override those of the parent, whose registrations
+// are shared rather than copied. The child starts with the settings of the parent,
+// i.e. its maximum depth, interface scan, strict mode, rejection of zero values and
+// MultiPolicy,
+// which it can then change on its own; the resolve hooks and middlewares of the parent
+// apply to the child as they stay in effect for resolutions falling back to the parent.
+// The parent disposes the child along with itself until the child is disposed on its
+// own or no longer referenced.
+func NewChild(parent *DependencyInjection) *DependencyInjection {
+	child := NewDependencyInjection()
+	child.info.parent = parent
+
+	parent.info.mutex.Lock()
+	parent.info.children = append(parent.info.children, child.info)
+	child.info.maxDepth = parent.info.maxDepth
+	child.info.noInterfaceScan = parent.info.noInterfaceScan
+	child.info.strict = parent.info.strict
+	child.info.rejectZero = parent.info.rejectZero
+	child.info.multi = parent.info.multi
+	child.info.debug.Store(parent.info.debug.Load())
+	parent.info.mutex.Unlock()
+
+	var info = child.info
+	runtime.SetFinalizer(child, func(*DependencyInjection) {
+		parent.info.detach(info)
+	})
+	return child
+}
+
+// detach forgets the child container, which is no longer disposed along with info.
+func (info *dependencyInjection) detach(child *dependencyInjection) {
+	info.mutex.Lock()
+	for i, c := range info.children {
+		if c == child {
+			info.children = append(info.children[:i], info.children[i+1:]...)
+			break
+		}
+	}
+	info.mutex.Unlock()
+}
+
+// visitedSet records the containers visited while walking up the parent chain,
+// so that a cycle ends the walk instead of looping forever.
+type visitedSet struct {
+	small [4]*dependencyInjection
+	n     int
+	more  map[*dependencyInjection]struct{}
+}
+
+// add records info as visited, reporting false if it already was.
+func (v *visitedSet) add(info *dependencyInjection) bool {
+	for i := 0; i < v.n; i++ {
+		if v.small[i] == info {
+			return false
+		}
+	}
+	if _, ok := v.more[info]; ok {
+		return false
+	}
+	if v.n < len(v.small) {
+		v.small[v.n] = info
+		v.n++
+		return true
+	}
+	if v.more == nil {
+		v.more = make(map[*dependencyInjection]struct{})
+	}
+	v.more[info] = struct{}{}
+	return true
 }
 
 // Ptr returns the pointer to any variable. Useful to make reference to values returned by MustAny() or MustNeed()
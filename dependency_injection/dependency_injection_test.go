@@ -0,0 +1,734 @@
+package dependency_injection
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/martinarisk/di/dependency_injection/internal/one"
+	"github.com/martinarisk/di/dependency_injection/internal/two"
+)
+
+type testConfig struct {
+	name string
+}
+
+type testGreeter interface {
+	Greet() string
+}
+
+type testEnglish struct {
+	accent string
+}
+
+func (*testEnglish) Greet() string { return "hello" }
+
+func TestTryAnyMiss(t *testing.T) {
+	di := NewDependencyInjection()
+
+	if got, ok := TryAny[*testConfig](di); ok || got != nil {
+		t.Fatalf("TryAny on an empty container = %v, %v; want nil, false", got, ok)
+	}
+}
+
+func TestTryAnyHit(t *testing.T) {
+	di := NewDependencyInjection()
+	config := &testConfig{name: "app"}
+	di.Add(config)
+
+	if got, ok := TryAny[*testConfig](di); !ok || got != config {
+		t.Fatalf("TryAny = %v, %v; want the registered config", got, ok)
+	}
+}
+
+func TestTryAnyNested(t *testing.T) {
+	parent := NewDependencyInjection()
+	config := &testConfig{name: "parent"}
+	parent.Add(config)
+	child := NewChild(NewChild(parent))
+
+	if got, ok := TryAny[*testConfig](child); !ok || got != config {
+		t.Fatalf("TryAny from a grandchild = %v, %v; want the parent's config", got, ok)
+	}
+	if _, ok := TryAny[*testEnglish](child); ok {
+		t.Fatal("TryAny found a type registered nowhere in the chain")
+	}
+}
+
+func TestHasInterface(t *testing.T) {
+	di := NewDependencyInjection()
+	if Has[testGreeter](di) {
+		t.Fatal("Has[testGreeter] on an empty container = true")
+	}
+	di.Add(&testEnglish{})
+
+	if !Has[testGreeter](di) {
+		t.Fatal("Has[testGreeter] = false with a *testEnglish registered")
+	}
+	if !Has[*testEnglish](di) {
+		t.Fatal("Has[*testEnglish] = false for the concrete type")
+	}
+	if Has[*testConfig](di) {
+		t.Fatal("Has[*testConfig] = true with no config registered")
+	}
+}
+
+func TestHasFactoryNotInvoked(t *testing.T) {
+	di := NewDependencyInjection()
+	var calls int
+	AddFactory(di, func(*DependencyInjection) *testConfig {
+		calls++
+		return &testConfig{}
+	})
+	if !Has[*testConfig](NewChild(di)) || calls != 0 {
+		t.Fatalf("Has with a factory in the parent = false or invoked it %d times", calls)
+	}
+}
+
+func TestReplaceLeavesNoDuplicate(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&testConfig{name: "real"})
+	di.Add(&testConfig{name: "other"})
+
+	mock := &testConfig{name: "mock"}
+	Replace(di, mock)
+
+	if all := All[*testConfig](di); len(all) != 1 || all[0] != mock {
+		t.Fatalf("All after Replace = %v, want only the mock", all)
+	}
+	if got := MustAny[*testConfig](di); got != mock {
+		t.Fatalf("MustAny after Replace = %v, want the mock", got)
+	}
+}
+
+func TestReplaceConcurrentNeverEmpty(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&testConfig{name: "0"})
+
+	var done = make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			Replace(di, &testConfig{name: "next"})
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if _, ok := TryAny[*testConfig](di); !ok {
+			t.Fatal("Any observed an empty bucket during Replace")
+		}
+	}
+}
+
+func TestClear(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&testConfig{name: "old"})
+	di.AddNamed("old", 1)
+	AddFactory(di, func(*DependencyInjection) *testEnglish { return &testEnglish{} })
+
+	di.Clear()
+	if _, ok := TryAny[*testConfig](di); ok {
+		t.Fatal("value added before Clear still resolves")
+	}
+	if _, ok := TryAny[*testEnglish](di); ok {
+		t.Fatal("factory added before Clear still resolves")
+	}
+	if _, err := Named[int](di, "old"); err == nil {
+		t.Fatal("name added before Clear still resolves")
+	}
+
+	config := &testConfig{name: "new"}
+	di.Add(config)
+	if got, ok := TryAny[*testConfig](di); !ok || got != config {
+		t.Fatalf("Add after Clear = %v, %v; want the new value", got, ok)
+	}
+}
+
+func TestClearKeepsParent(t *testing.T) {
+	parent := NewDependencyInjection()
+	parent.Add(&testConfig{name: "parent"})
+	child := NewChild(parent)
+	child.Clear()
+
+	if _, ok := TryAny[*testConfig](child); !ok {
+		t.Fatal("Clear dropped the link to the parent")
+	}
+}
+
+func TestRemoveKeepsOtherInBothPaths(t *testing.T) {
+	di := NewDependencyInjection()
+	first, second := &testEnglish{}, &testEnglish{}
+	di.Add(first)
+	di.Add(second)
+
+	di.Remove(second)
+	if got := MustAny[*testEnglish](di); got != first {
+		t.Fatalf("typed resolution after Remove = %p, want the remaining %p", got, first)
+	}
+	if got := MustAny[testGreeter](di); got != first {
+		t.Fatalf("interface resolution after Remove = %p, want the remaining %p", got, first)
+	}
+	if all := All[testGreeter](di); len(all) != 1 {
+		t.Fatalf("All after Remove = %d, want 1", len(all))
+	}
+	if di.ContainsInstance(second) {
+		t.Fatal("removed value is still contained")
+	}
+}
+
+type testStore interface {
+	Get(key string) string
+}
+
+type testMemoryStore struct {
+	data map[string]string
+}
+
+func (s *testMemoryStore) Get(key string) string { return s.data[key] }
+
+func TestAddAsResolvesByInterfaceKey(t *testing.T) {
+	di := NewDependencyInjection()
+	di.EnableInterfaceScan(false)
+	for i := 0; i < 100; i++ {
+		di.Add(&testConfig{name: "unrelated"})
+	}
+	store := &testMemoryStore{}
+	AddAs[testStore](di, store)
+
+	if got, ok := TryAny[testStore](di); !ok || got != store {
+		t.Fatalf("TryAny[testStore] without interface scan = %v, %v; want the store added with AddAs", got, ok)
+	}
+	if _, ok := TryAny[*testMemoryStore](di); ok {
+		t.Fatal("value added with AddAs resolved under its concrete type without interface scan")
+	}
+}
+
+func TestSameNamedTypesOfDifferentPackages(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&one.Config{Name: "one"})
+	di.Add(&two.Config{Name: "two"})
+
+	if got := MustAny[*one.Config](di); got.Name != "one" {
+		t.Fatalf("MustAny[*one.Config] = %q, want one", got.Name)
+	}
+	if got := MustAny[*two.Config](di); got.Name != "two" {
+		t.Fatalf("MustAny[*two.Config] = %q, want two", got.Name)
+	}
+	if n := di.Len(); n != 2 {
+		t.Fatalf("Len = %d, want 2 distinct type keys", n)
+	}
+}
+
+func TestAddKeysByDynamicType(t *testing.T) {
+	di := NewDependencyInjection()
+	var greeter testGreeter = &testEnglish{accent: "british"}
+	di.Add(greeter)
+
+	if got, ok := TryAny[*testEnglish](di); !ok || got != greeter {
+		t.Fatal("value added through an interface variable did not resolve as its concrete type")
+	}
+	if got, ok := TryAny[testGreeter](di); !ok || got != greeter {
+		t.Fatal("value added through an interface variable did not resolve as the interface")
+	}
+	if keys := di.Keys(); len(keys) != 1 || keys[0] != "*github.com/martinarisk/di/dependency_injection.testEnglish" {
+		t.Fatalf("Keys = %v, want the concrete type only", keys)
+	}
+}
+
+func TestAddTypedKeysByStaticType(t *testing.T) {
+	di := NewDependencyInjection()
+	di.EnableInterfaceScan(false)
+	english := &testEnglish{accent: "british"}
+	AddTyped[testGreeter](di, english)
+	AddTyped(di, &testConfig{name: "app"})
+
+	if got, ok := TryAny[testGreeter](di); !ok || got != english {
+		t.Fatal("AddTyped with an interface type did not resolve under the interface")
+	}
+	if _, ok := TryAny[*testEnglish](di); ok {
+		t.Fatal("AddTyped with an interface type resolved under the concrete type")
+	}
+	if _, ok := TryAny[*testConfig](di); !ok {
+		t.Fatal("AddTyped with a concrete type did not resolve under it")
+	}
+}
+
+func TestRangeOrder(t *testing.T) {
+	di := NewDependencyInjection()
+	first, second := &testConfig{name: "first"}, &testConfig{name: "second"}
+	di.Add(first)
+	di.Add(&testEnglish{})
+	di.Add(second)
+
+	var got []interface{}
+	di.Range(func(key string, dep interface{}) bool {
+		got = append(got, dep)
+		return true
+	})
+	if len(got) != 3 || got[0] != first || got[1] != second {
+		t.Fatalf("Range = %v, want the configs in registration order before the *testEnglish", got)
+	}
+
+	var calls int
+	di.Range(func(string, interface{}) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Fatalf("Range called f %d times after it returned false, want 1", calls)
+	}
+}
+
+func TestRangeWhileAdding(t *testing.T) {
+	di := NewDependencyInjection()
+	for i := 0; i < 100; i++ {
+		di.Add(&testConfig{name: "seed"})
+	}
+
+	var done = make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			di.Add(&testConfig{name: "added"})
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		var n int
+		di.Range(func(key string, dep interface{}) bool {
+			if _, ok := dep.(*testConfig); !ok {
+				t.Errorf("Range yielded %T", dep)
+			}
+			n++
+			return true
+		})
+		if n < 100 {
+			t.Fatalf("Range yielded %d dependencies, want at least 100", n)
+		}
+	}
+}
+
+func TestRangeMayMutate(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&testConfig{name: "a"})
+	di.Add(&testConfig{name: "b"})
+
+	di.Range(func(key string, dep interface{}) bool {
+		di.Remove(dep)
+		return true
+	})
+	if di.Len() != 0 {
+		t.Fatalf("Len after removing every dependency in Range = %d", di.Len())
+	}
+}
+
+func TestChildOverridesParent(t *testing.T) {
+	parent := NewDependencyInjection()
+	parent.Add(&testConfig{name: "parent"})
+	parent.Add(&testEnglish{accent: "parent"})
+	child := NewChild(parent)
+	override := &testConfig{name: "child"}
+	child.Add(override)
+
+	if got := MustAny[*testConfig](child); got != override {
+		t.Fatalf("child resolved %q, want its own override", got.name)
+	}
+	if got := MustAny[*testEnglish](child); got.accent != "parent" {
+		t.Fatal("type not overridden by the child did not come from the parent")
+	}
+	if got := MustAny[*testConfig](parent); got.name != "parent" {
+		t.Fatal("child override leaked into the parent")
+	}
+	if child.Parent() != parent || child.Root() != parent {
+		t.Fatal("Parent or Root of the child is not the parent")
+	}
+}
+
+func TestRemoveType(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&testConfig{name: "a"})
+	di.Add(&testConfig{name: "b"})
+	di.Add(&testEnglish{})
+
+	RemoveType[*testConfig](di)
+	var config *testConfig
+	if err := Any(di, &config); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("Any after RemoveType = %v, want ErrDependencyNotFound", err)
+	}
+	if _, ok := TryAny[*testEnglish](di); !ok {
+		t.Fatal("RemoveType removed an unrelated type")
+	}
+}
+
+func TestAnyOr(t *testing.T) {
+	di := NewDependencyInjection()
+	def := &testConfig{name: "default"}
+
+	if got := AnyOr(di, def); got != def {
+		t.Fatal("AnyOr on a miss did not return the default")
+	}
+	if Has[*testConfig](di) {
+		t.Fatal("AnyOr registered the default")
+	}
+	config := &testConfig{name: "registered"}
+	di.Add(config)
+	if got := AnyOr(di, def); got != config {
+		t.Fatal("AnyOr on a hit did not return the registered value")
+	}
+	if got := AnyOr[*testConfig](nil, def); got != def {
+		t.Fatal("AnyOr on a nil container did not return the default")
+	}
+}
+
+func TestAddNilIsNoOp(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(nil)
+	var greeter testGreeter
+	di.Add(greeter)
+	di.AddAll(nil, nil)
+	di.Remove(nil)
+
+	if di.Len() != 0 || len(di.Keys()) != 0 {
+		t.Fatalf("Add(nil) registered something: Len = %d", di.Len())
+	}
+	if di.ContainsInstance(nil) {
+		t.Fatal("ContainsInstance(nil) = true")
+	}
+
+	config := &testConfig{name: "after"}
+	di.Add(config)
+	if got, ok := TryAny[*testConfig](di); !ok || got != config {
+		t.Fatal("container unusable after Add(nil)")
+	}
+	if _, ok := TryAny[testGreeter](di); ok {
+		t.Fatal("nil interface value resolved")
+	}
+}
+
+func TestSameNamedGenericTypesOfDifferentPackages(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&one.Box[int]{Value: 1})
+	di.Add(&two.Box[int]{Value: 2})
+	di.Add(&one.Box[string]{Value: "one"})
+
+	if got := MustAny[*one.Box[int]](di); got.Value != 1 {
+		t.Fatalf("MustAny[*one.Box[int]] = %d, want 1", got.Value)
+	}
+	if got := MustAny[*two.Box[int]](di); got.Value != 2 {
+		t.Fatalf("MustAny[*two.Box[int]] = %d, want 2", got.Value)
+	}
+	if got := MustAny[*one.Box[string]](di); got.Value != "one" {
+		t.Fatalf("MustAny[*one.Box[string]] = %q, want one", got.Value)
+	}
+
+	var keys = make(map[string]bool)
+	for _, key := range di.Keys() {
+		keys[key] = true
+	}
+	if len(keys) != 3 {
+		t.Fatalf("Keys = %v, want 3 distinct names", di.Keys())
+	}
+}
+
+func TestSameNamedTypesWithinCompositeTypes(t *testing.T) {
+	var pairs = []struct{ a, b reflect.Type }{
+		{reflect.TypeOf(make(chan one.Config)), reflect.TypeOf(make(chan two.Config))},
+		{reflect.TypeOf(make(<-chan one.Config)), reflect.TypeOf(make(<-chan two.Config))},
+		{reflect.TypeOf(func(one.Config) {}), reflect.TypeOf(func(two.Config) {})},
+		{reflect.TypeOf(func() one.Config { return one.Config{} }), reflect.TypeOf(func() two.Config { return two.Config{} })},
+		{reflect.TypeOf(func(...one.Config) {}), reflect.TypeOf(func(...two.Config) {})},
+		{reflect.TypeOf(struct{ C one.Config }{}), reflect.TypeOf(struct{ C two.Config }{})},
+	}
+	for _, p := range pairs {
+		if typeName(p.a) == typeName(p.b) {
+			t.Errorf("%v and %v are both named %s", p.a, p.b, typeName(p.a))
+		}
+	}
+
+	for _, v := range []interface{}{
+		make(chan int), make(chan<- string), make(chan (<-chan int)),
+		func(int, ...string) (bool, error) { return false, nil },
+		func() error { return nil },
+		struct {
+			A int `json:"a"`
+			B []string
+		}{},
+		struct{}{},
+	} {
+		if got, want := typeName(reflect.TypeOf(v)), reflect.TypeOf(v).String(); got != want {
+			t.Errorf("typeName = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestAnyAllParentAndChild(t *testing.T) {
+	parent := NewDependencyInjection()
+	fromParent := &testEnglish{accent: "parent"}
+	parent.Add(fromParent)
+	child := NewChild(parent)
+	fromChild := &testPerson{name: "child"}
+	child.Add(fromChild)
+
+	var existing = &testEnglish{accent: "existing"}
+	var out = []testGreeter{existing}
+	if err := AnyAll(child, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 3 || out[0] != existing || out[1] != fromChild || out[2] != fromParent {
+		t.Fatalf("AnyAll = %v, want the existing element, then the child's, then the parent's", out)
+	}
+
+	var none []testStore
+	if err := AnyAll(child, &none); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("AnyAll without matches = %v, want ErrDependencyNotFound", err)
+	}
+}
+
+func TestFreezePanicsOnMutate(t *testing.T) {
+	di := NewDependencyInjection()
+	config := &testConfig{}
+	di.Add(config)
+	di.Freeze()
+
+	for name, mutate := range map[string]func(){
+		"Add":        func() { di.Add(&testEnglish{}) },
+		"AddAll":     func() { di.AddAll(&testEnglish{}) },
+		"Remove":     func() { di.Remove(config) },
+		"Replace":    func() { Replace(di, &testConfig{}) },
+		"RemoveType": func() { RemoveType[*testConfig](di) },
+		"Clear":      func() { di.Clear() },
+		"AddNamed":   func() { di.AddNamed("x", 1) },
+		"AddFactory": func() { AddFactory(di, func(*DependencyInjection) *testEnglish { return nil }) },
+	} {
+		if v := panicOf(t, mutate); v != ErrFrozen {
+			t.Errorf("%s on a frozen container panicked with %v, want ErrFrozen", name, v)
+		}
+	}
+	if got, ok := TryAny[*testConfig](di); !ok || got != config {
+		t.Fatal("frozen container lost its registration")
+	}
+}
+
+func TestFreezeAllowsCachingAndChildren(t *testing.T) {
+	di := NewDependencyInjection()
+	AddFactory(di, func(*DependencyInjection) *testEnglish { return &testEnglish{} })
+	di.Freeze()
+
+	if first := MustAny[*testEnglish](di); first != MustAny[*testEnglish](di) {
+		t.Fatal("factory result not cached in a frozen container")
+	}
+	MustNeed(di, func(*DependencyInjection) *testConfig { return &testConfig{} })
+	NewChild(di).Add(&testConfig{})
+}
+
+func TestAddSelfRejected(t *testing.T) {
+	di := NewDependencyInjection()
+	if err, _ := panicOf(t, func() { di.Add(di) }).(error); !errors.Is(err, ErrSelfRegistration) {
+		t.Fatalf("Add of the container to itself panicked with %v, want ErrSelfRegistration", err)
+	}
+
+	done := make(chan bool)
+	go func() {
+		_, ok := TryAny[*testConfig](di)
+		done <- ok
+	}()
+	select {
+	case ok := <-done:
+		if ok {
+			t.Fatal("miss resolved a dependency")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("miss after Add of the container to itself did not terminate")
+	}
+
+	child := NewChild(di)
+	child.Add(di)
+	if got := MustAny[*DependencyInjection](child); got != di {
+		t.Fatal("child could not register its parent")
+	}
+}
+
+func TestAddOrKeep(t *testing.T) {
+	di := NewDependencyInjection()
+	first := &testMemoryStore{}
+	if !AddOrKeep[testStore](di, first) {
+		t.Fatal("AddOrKeep on an empty container did not add")
+	}
+	if AddOrKeep[testStore](di, &testMemoryStore{}) {
+		t.Fatal("AddOrKeep added a second testStore")
+	}
+	if got := MustAny[testStore](di); got != first {
+		t.Fatal("AddOrKeep replaced the registered testStore")
+	}
+
+	AddFactory(di, func(*DependencyInjection) *testConfig { return &testConfig{name: "factory"} })
+	if AddOrKeep(di, &testConfig{name: "default"}) {
+		t.Fatal("AddOrKeep added next to a factory for the type")
+	}
+	var nothing testStore
+	if AddOrKeep(NewDependencyInjection(), nothing) {
+		t.Fatal("AddOrKeep reported adding nil")
+	}
+}
+
+func TestAddOrKeepConcurrent(t *testing.T) {
+	di := NewDependencyInjection()
+	var added atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if AddOrKeep(di, &testConfig{name: strconv.Itoa(i)}) {
+				added.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := added.Load(); n != 1 || len(All[*testConfig](di)) != 1 {
+		t.Fatalf("concurrent AddOrKeep added %d times, want 1", n)
+	}
+}
+
+func TestAnyFunc(t *testing.T) {
+	di := NewDependencyInjection()
+	config := &testConfig{name: "app"}
+	di.Add(config)
+
+	failed := errors.New("use failed")
+	var got *testConfig
+	err := AnyFunc(di, func(c *testConfig) error {
+		got = c
+		return failed
+	})
+	if got != config || !errors.Is(err, failed) {
+		t.Fatalf("AnyFunc called use with %v and returned %v, want the config and its error", got, err)
+	}
+
+	var called bool
+	err = AnyFunc(di, func(testStore) error {
+		called = true
+		return nil
+	})
+	if called || !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("AnyFunc of a missing type called use: %v, returned %v", called, err)
+	}
+}
+
+func TestLazyResolvesOnFirstCall(t *testing.T) {
+	di := NewDependencyInjection()
+	get := Lazy[*testConfig](di)
+
+	if _, err := get(); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("Lazy before registration = %v, want ErrDependencyNotFound", err)
+	}
+	di.Add(&testConfig{name: "old"})
+	latest := &testConfig{name: "latest"}
+	di.Add(latest)
+	if got, err := get(); err != nil || got != latest {
+		t.Fatalf("Lazy = %v, %v; want the latest registration at call time", got, err)
+	}
+
+	di.Add(&testConfig{name: "later"})
+	if got, _ := get(); got != latest {
+		t.Fatalf("Lazy = %v after another registration, want the cached %v", got, latest)
+	}
+}
+
+func TestResolveThroughParentsWhileMutated(t *testing.T) {
+	root := NewDependencyInjection()
+	root.Add(&testConfig{name: "root"})
+	middle := NewScopedDependencyInjection(root)
+	leaf := NewScopedDependencyInjection(middle)
+
+	var stop = make(chan struct{})
+	var mutated = make(chan struct{})
+	go func() {
+		defer close(mutated)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			english := &testEnglish{accent: strconv.Itoa(i)}
+			root.Add(english)
+			middle.AddNamed("n", i)
+			root.Remove(english)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				if got := MustAny[*testConfig](leaf); got.name != "root" {
+					t.Errorf("resolved %v through the parents, want the root config", got)
+					return
+				}
+				TryAny[*testEnglish](leaf)
+				_, _ = Named[int](leaf, "n")
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	<-mutated
+}
+
+func TestMustAnyPanicNamesType(t *testing.T) {
+	di := NewDependencyInjection()
+	for _, tc := range []struct {
+		name string
+		must func()
+		want string
+	}{
+		{"pointer", func() { MustAny[*testConfig](di) }, "dependency not found: *dependency_injection.testConfig"},
+		{"interface", func() { MustAny[testNamer](di) }, "dependency not found: dependency_injection.testNamer"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			msg, _ := panicOf(t, tc.must).(string)
+			if !strings.Contains(msg, tc.want) {
+				t.Fatalf("MustAny panicked with %q, want it to contain %q", msg, tc.want)
+			}
+		})
+	}
+}
+
+func TestAnyFromReportsLevel(t *testing.T) {
+	root := NewDependencyInjection()
+	root.Add(&testConfig{name: "root"})
+	root.Add(&testEnglish{accent: "root"})
+	scope := NewScopedDependencyInjection(root)
+	override := &testConfig{name: "scope"}
+	scope.Add(override)
+	leaf := NewChild(scope)
+
+	if got, level, err := AnyFrom[*testConfig](scope); err != nil || got != override || level != 0 {
+		t.Fatalf("AnyFrom of the override = %v, %d, %v; want the child config at level 0", got, level, err)
+	}
+	if got, level, err := AnyFrom[*testEnglish](scope); err != nil || got.accent != "root" || level != 1 {
+		t.Fatalf("AnyFrom of a parent-only dependency = %v, %d, %v; want level 1", got, level, err)
+	}
+	if _, level, err := AnyFrom[*testEnglish](leaf); err != nil || level != 2 {
+		t.Fatalf("AnyFrom through two parents = level %d, %v; want level 2", level, err)
+	}
+	if _, _, err := AnyFrom[*testPerson](leaf); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("AnyFrom of a missing type = %v, want ErrDependencyNotFound", err)
+	}
+}
@@ -0,0 +1,87 @@
+package dependency_injection
+
+import (
+	"sort"
+)
+
+// Registration describes how a dependency is registered, as returned by Describe(...).
+type Registration struct {
+	// Type is the type key of the dependency, named with its full package path like Keys().
+	Type string
+	// Lifetime is the lifetime of the factory, or for an instance that of its container.
+	Lifetime Lifetime
+	// Factory reports whether the dependency is made by a factory, e.g. one registered
+	// with AddFactory or AddScoped, rather than registered as an instance.
+	Factory bool
+	// Name is the name of a dependency registered with AddNamed, or empty.
+	Name string
+	// Group is the group of a dependency added with AddToGroup, or empty.
+	Group string
+	// Level is the number of parents between the container described and the one
+	// providing the registration: 0 for the container itself, 1 for its parent, etc.
+	Level int
+	// Federated reports whether a container federated with Federate(...) provides the
+	// registration, in which case Level is that of the container federating it.
+	Federated bool
+}
+
+// Describe returns the registration of the dependency of type T, reporting whether
+// there is one, without resolving it: the nearest of the container, its parents and the
+// containers they federate providing T, and within it a factory for T, a type-keyed
+// instance, a named one or a group member, in that order and by sorted name or group.
+// Objects made by a Scoped factory are described as instances of the container caching them.
+func Describe[T any](di *DependencyInjection) (reg Registration, ok bool) {
+	var t0 = keyFor[T]()
+
+	di.walk(func(c *DependencyInjection, level int, federated bool) bool {
+		reg = Registration{Type: typeName(t0), Lifetime: c.Lifetime(), Level: level, Federated: federated}
+		if f := c.factoryOf(t0); f != nil {
+			reg.Lifetime, reg.Factory = f.lifetime, true
+			ok = true
+			return false
+		}
+		if _, ok = find[T](c, t0); ok {
+			return false
+		}
+
+		c.info.mutex.RLock()
+		reg.Name, reg.Group = c.info.labelOf(is[T])
+		c.info.mutex.RUnlock()
+		ok = reg.Name != "" || reg.Group != ""
+		return !ok
+	})
+	if !ok {
+		return Registration{}, false
+	}
+	return reg, true
+}
+
+// labelOf returns the first sorted name, or else group, holding a dependency satisfying
+// match, or empty strings if there is none. It must be called with the lock held.
+func (info *dependencyInjection) labelOf(match func(dep interface{}) bool) (name, group string) {
+	var names = make([]string, 0, len(info.named))
+	for n, dep := range info.named {
+		if match(dep) {
+			names = append(names, n)
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		return names[0], ""
+	}
+
+	var groups []string
+	for g, members := range info.groups {
+		for _, dep := range members {
+			if match(dep) {
+				groups = append(groups, g)
+				break
+			}
+		}
+	}
+	if len(groups) > 0 {
+		sort.Strings(groups)
+		return "", groups[0]
+	}
+	return "", ""
+}
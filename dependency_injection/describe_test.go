@@ -0,0 +1,35 @@
+package dependency_injection
+
+import "testing"
+
+func TestDescribe(t *testing.T) {
+	root := NewDependencyInjection()
+	root.Add(&testConfig{name: "root"})
+	AddScoped(root, func(*DependencyInjection) *lifetimeCounter { return &lifetimeCounter{} })
+	root.AddNamed("primary", &testPerson{name: "primary"})
+	root.AddToGroup("handlers", &testEnglish{})
+	scope := NewScopedDependencyInjection(root)
+	scope.Add(testRegion(1))
+
+	for _, tc := range []struct {
+		name string
+		got  func(*DependencyInjection) (Registration, bool)
+		want Registration
+	}{
+		{"eager", Describe[testRegion], Registration{Type: "github.com/martinarisk/di/dependency_injection.testRegion", Lifetime: Scoped}},
+		{"parent", Describe[*testConfig], Registration{Type: "*github.com/martinarisk/di/dependency_injection.testConfig", Level: 1}},
+		{"factory", Describe[*lifetimeCounter], Registration{Type: "*github.com/martinarisk/di/dependency_injection.lifetimeCounter", Lifetime: Scoped, Factory: true, Level: 1}},
+		{"named", Describe[testNamer], Registration{Type: "github.com/martinarisk/di/dependency_injection.testNamer", Name: "primary", Level: 1}},
+		{"grouped", Describe[*testEnglish], Registration{Type: "*github.com/martinarisk/di/dependency_injection.testEnglish", Group: "handlers", Level: 1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, ok := tc.got(scope); !ok || got != tc.want {
+				t.Fatalf("Describe = %+v, %v; want %+v", got, ok, tc.want)
+			}
+		})
+	}
+
+	if got, ok := Describe[*testShard](scope); ok {
+		t.Fatalf("Describe of an unregistered type = %+v, want none", got)
+	}
+}
@@ -0,0 +1,152 @@
+package dependency_injection
+
+import (
+	"errors"
+	"io"
+)
+
+// AddWithCleanup registers a dependency within the container together with a cleanup
+// function that Dispose() runs before closing the dependency.
+func (di *DependencyInjection) AddWithCleanup(dep interface{}, cleanup func() error) {
+	checkIdentity(dep)
+
+	di.info.mutex.Lock()
+
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		return
+	}
+	di.info.admit(dep)
+	if dep == nil {
+		di.info.mutex.Unlock()
+		return
+	}
+
+	var t0 = typeKey(dep)
+	di.info.register(t0, dep)
+
+	if di.info.cleanups == nil {
+		di.info.cleanups = make(map[interface{}][]func() error)
+	}
+	di.info.cleanups[identity(dep)] = append(di.info.cleanups[identity(dep)], cleanup)
+
+	di.info.mutex.Unlock()
+}
+
+// Dispose runs the cleanup functions of every dependency registered within the container
+// and closes those that implement io.Closer, in reverse registration order, then
+// unregisters them. Idle objects of a pooled container are closed and dropped too.
+// Child containers created with NewChild(...) and not yet disposed are disposed first,
+// the most recently created first. Parent containers are not disposed. All cleanups
+// run even if some fail, and their errors are joined together.
+func (di *DependencyInjection) Dispose() error {
+	var errs []error
+
+	di.info.mutex.Lock()
+	var children = append([]*dependencyInjection(nil), di.info.children...)
+	di.info.mutex.Unlock()
+
+	for i := len(children) - 1; i >= 0; i-- {
+		if err := (&DependencyInjection{info: children[i]}).Dispose(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if parent := di.Parent(); parent != nil {
+		parent.info.detach(di.info)
+	}
+
+	di.info.mutex.Lock()
+
+	var disposed, cleanups = di.info.disposal(di.info.registered())
+	for _, dep := range disposed {
+		di.info.unregister(dep)
+	}
+	di.info.publish()
+	if di.info.pool != nil {
+		for _, obj := range di.info.pool.drain() {
+			if closer, ok := (obj).(io.Closer); ok {
+				cleanups = append(cleanups, closer.Close)
+			}
+		}
+	}
+
+	di.info.mutex.Unlock()
+
+	return errors.Join(append(errs, cleanUp(cleanups))...)
+}
+
+// RemoveTypeAndDispose unregisters every dependency registered under the type key of T
+// like RemoveType(...), and runs the cleanup functions of those removed and closes those
+// that implement io.Closer, in reverse registration order, like Dispose(). The object
+// made by a factory of T within the container, and those of Scoped factories cached by
+// the container, are forgotten too, so the next resolution makes a fresh one. It suits
+// hot reloads swapping out a connection. All cleanups run even if some fail, and their
+// errors are joined together.
+func RemoveTypeAndDispose[T any](di *DependencyInjection) error {
+	di.info.mutex.Lock()
+
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		return nil
+	}
+
+	var t0 = keyFor[T]()
+	var removed = append([]interface{}(nil), di.info.order[t0]...)
+	if f := di.info.factories[t0]; f != nil {
+		if dep, ok := f.singleton.reset(); ok && !containsIdentity(removed, dep) {
+			removed = append(removed, dep)
+		}
+	}
+	for f, inst := range di.info.instances {
+		if dep, ok := inst.get(); ok && containsIdentity(removed, dep) {
+			delete(di.info.instances, f)
+		}
+	}
+	var _, cleanups = di.info.disposal(removed)
+	for _, dep := range removed {
+		di.info.unregister(dep)
+	}
+
+	di.info.mutex.Unlock()
+	return cleanUp(cleanups)
+}
+
+// containsIdentity reports whether dep is one of deps.
+func containsIdentity(deps []interface{}, dep interface{}) bool {
+	for _, d := range deps {
+		if identity(d) == identity(dep) {
+			return true
+		}
+	}
+	return false
+}
+
+// disposal returns those of deps that need disposing, and the functions disposing them
+// in registration order: for each, its Close method if it implements io.Closer, followed
+// by its cleanup functions, so that running them in reverse cleans up before closing.
+// It must be called with the lock held.
+func (info *dependencyInjection) disposal(deps []interface{}) (disposed []interface{}, cleanups []func() error) {
+	for _, dep := range deps {
+		var n = len(cleanups)
+		if closer, ok := (dep).(io.Closer); ok {
+			cleanups = append(cleanups, closer.Close)
+		}
+		cleanups = append(cleanups, info.cleanups[identity(dep)]...)
+		if len(cleanups) > n {
+			disposed = append(disposed, dep)
+		}
+	}
+	return
+}
+
+// cleanUp runs the cleanups in reverse order, and returns their errors joined together.
+func cleanUp(cleanups []func() error) error {
+	var errs []error
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		if err := cleanups[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
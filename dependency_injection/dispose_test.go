@@ -0,0 +1,174 @@
+package dependency_injection
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+// disposeClock hands out increasing timestamps for recording the order of closes.
+type disposeClock struct {
+	now int
+}
+
+type disposeCloser struct {
+	name     string
+	clock    *disposeClock
+	closedAt int
+	err      error
+}
+
+func (c *disposeCloser) Close() error {
+	c.clock.now++
+	c.closedAt = c.clock.now
+	return c.err
+}
+
+func TestDisposeChildrenFirst(t *testing.T) {
+	clock := &disposeClock{}
+	parent := NewDependencyInjection()
+	first, second := NewChild(parent), NewChild(parent)
+
+	p := &disposeCloser{name: "parent", clock: clock}
+	c1 := &disposeCloser{name: "first", clock: clock}
+	c2 := &disposeCloser{name: "second", clock: clock}
+	parent.Add(p)
+	first.Add(c1)
+	second.Add(c2)
+
+	if err := parent.Dispose(); err != nil {
+		t.Fatal(err)
+	}
+	if c2.closedAt != 1 || c1.closedAt != 2 || p.closedAt != 3 {
+		t.Fatalf("closed at parent=%d first=%d second=%d, want the last child first and the parent last",
+			p.closedAt, c1.closedAt, c2.closedAt)
+	}
+}
+
+func TestDisposeReverseOrderAndJoinedErrors(t *testing.T) {
+	clock := &disposeClock{}
+	di := NewDependencyInjection()
+	errA, errB := errors.New("a failed"), errors.New("b failed")
+	a := &disposeCloser{name: "a", clock: clock, err: errA}
+	b := &disposeCloser{name: "b", clock: clock, err: errB}
+	di.Add(a)
+	di.Add(b)
+
+	var cleanedAt int
+	di.AddWithCleanup(&testConfig{}, func() error {
+		clock.now++
+		cleanedAt = clock.now
+		return nil
+	})
+
+	err := di.Dispose()
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("Dispose = %v, want both close errors joined", err)
+	}
+	if cleanedAt != 1 || b.closedAt != 2 || a.closedAt != 3 {
+		t.Fatalf("cleanup at %d, b closed at %d, a closed at %d; want reverse registration order",
+			cleanedAt, b.closedAt, a.closedAt)
+	}
+	if _, ok := TryAny[*disposeCloser](di); ok {
+		t.Fatal("disposed closer still registered")
+	}
+}
+
+func TestDisposedChildNotDisposedAgain(t *testing.T) {
+	clock := &disposeClock{}
+	parent := NewDependencyInjection()
+	child := NewChild(parent)
+	c := &disposeCloser{clock: clock}
+	child.Add(c)
+
+	if err := child.Dispose(); err != nil {
+		t.Fatal(err)
+	}
+	child.Add(c)
+	if err := parent.Dispose(); err != nil {
+		t.Fatal(err)
+	}
+	if clock.now != 1 {
+		t.Fatalf("closer closed %d times, want the disposed child detached from its parent", clock.now)
+	}
+}
+
+func TestRemoveTypeAndDisposeClosesOnce(t *testing.T) {
+	di := NewDependencyInjection()
+	clock := &disposeClock{}
+	old := &disposeCloser{name: "old", clock: clock}
+	kept := &testConfig{name: "kept"}
+	di.Add(old)
+	di.Add(kept)
+
+	if err := RemoveTypeAndDispose[*disposeCloser](di); err != nil {
+		t.Fatal(err)
+	}
+	if clock.now != 1 || old.closedAt != 1 {
+		t.Fatalf("Close called %d times, want exactly once", clock.now)
+	}
+	if di.ContainsInstance(old) {
+		t.Fatal("RemoveTypeAndDispose left the closer registered")
+	}
+	if got := MustAny[*testConfig](di); got != kept {
+		t.Fatal("RemoveTypeAndDispose removed a dependency of another type")
+	}
+	if err := di.Dispose(); err != nil || clock.now != 1 {
+		t.Fatalf("Dispose after RemoveTypeAndDispose = %v, closed %d times; want the closer closed once", err, clock.now)
+	}
+}
+
+func TestRemoveTypeAndDisposeJoinsErrors(t *testing.T) {
+	di := NewDependencyInjection()
+	clock := &disposeClock{}
+	errClose, errCleanup := errors.New("close failed"), errors.New("cleanup failed")
+	first := &disposeCloser{name: "first", clock: clock, err: errClose}
+	second := &disposeCloser{name: "second", clock: clock}
+	var cleanedAt int
+	di.Add(first)
+	di.AddWithCleanup(second, func() error {
+		clock.now++
+		cleanedAt = clock.now
+		return errCleanup
+	})
+
+	err := RemoveTypeAndDispose[*disposeCloser](di)
+	if !errors.Is(err, errClose) || !errors.Is(err, errCleanup) {
+		t.Fatalf("RemoveTypeAndDispose = %v, want both errors joined", err)
+	}
+	if cleanedAt != 1 || second.closedAt != 2 || first.closedAt != 3 {
+		t.Fatalf("cleanup at %d, closes at %d and %d; want reverse registration order, cleanup before close",
+			cleanedAt, second.closedAt, first.closedAt)
+	}
+}
+
+func TestRemoveTypeAndDisposeRemakesFactoryObjects(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		add  func(di *DependencyInjection, newer func(*DependencyInjection) *disposeCloser)
+	}{
+		{"Singleton", func(di *DependencyInjection, newer func(*DependencyInjection) *disposeCloser) { AddFactory(di, newer) }},
+		{"Scoped", func(di *DependencyInjection, newer func(*DependencyInjection) *disposeCloser) { AddScoped(di, newer) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			di := NewDependencyInjection()
+			clock := &disposeClock{}
+			var made int
+			tc.add(di, func(*DependencyInjection) *disposeCloser {
+				made++
+				return &disposeCloser{name: strconv.Itoa(made), clock: clock}
+			})
+
+			old := MustAny[*disposeCloser](di)
+			if err := RemoveTypeAndDispose[*disposeCloser](di); err != nil {
+				t.Fatal(err)
+			}
+			if clock.now != 1 || old.closedAt != 1 {
+				t.Fatalf("Close of the factory object called %d times, want exactly once", clock.now)
+			}
+			if fresh := MustAny[*disposeCloser](di); fresh == old || made != 2 || fresh.closedAt != 0 {
+				t.Fatalf("resolution after RemoveTypeAndDispose = %+v after %d factory calls, want a fresh object", fresh, made)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package dependency_injection
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+type factory struct {
+	lifetime  Lifetime
+	build     func(di *DependencyInjection) interface{}
+	singleton made
+	params    []reflect.Type
+}
+
+// made holds the object of a Singleton factory, or of a Scoped factory for one scope,
+// once it has been made. Unlike with sync.Once, a factory that panics leaves it empty,
+// so the next resolution retries the factory.
+type made struct {
+	value interface{}
+	ok    bool
+	mutex sync.Mutex
+}
+
+// AddFactory registers a factory that lazily constructs the dependency of type T.
+// The factory runs once, on the first resolution of T, and its result is registered
+// within the container as a singleton for all later resolutions. A factory that panics
+// is run again on the next resolution.
+func AddFactory[T any](di *DependencyInjection, newer func(di *DependencyInjection) T) {
+	addFactory(di, Singleton, newer)
+}
+
+// AddSingleton registers a factory for the dependency of type T with the Singleton
+// lifetime: it is made once, within the container, and shared by every scope below it.
+func AddSingleton[T any](di *DependencyInjection, newer func(di *DependencyInjection) T) {
+	addFactory(di, Singleton, newer)
+}
+
+// AddScoped registers a factory for the dependency of type T with the Scoped lifetime:
+// it is made once per resolving container and registered there, so each scope
+// created below the container gets its own.
+func AddScoped[T any](di *DependencyInjection, newer func(di *DependencyInjection) T) {
+	addFactory(di, Scoped, newer)
+}
+
+// AddTransient registers a factory for the dependency of type T with the Transient
+// lifetime: a new object is made on every resolution and never cached.
+func AddTransient[T any](di *DependencyInjection, newer func(di *DependencyInjection) T) {
+	addFactory(di, Transient, newer)
+}
+
+// addFactory registers a factory for the dependency of type T with the given lifetime.
+func addFactory[T any](di *DependencyInjection, lifetime Lifetime, newer func(di *DependencyInjection) T) {
+	di.info.mutex.Lock()
+
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		return
+	}
+
+	di.info.setFactory(keyFor[T](), newFactory(lifetime, newer))
+
+	di.info.mutex.Unlock()
+}
+
+// setFactory registers the factory f under type key t, replacing any previous one.
+func (info *dependencyInjection) setFactory(t reflect.Type, f *factory) {
+	if info.factories == nil {
+		info.factories = make(map[reflect.Type]*factory)
+	}
+	info.factories[t] = f
+}
+
+// newFactory wraps a typed factory function for storage within the container.
+func newFactory[T any](lifetime Lifetime, newer func(di *DependencyInjection) T) *factory {
+	return &factory{lifetime: lifetime, build: func(di *DependencyInjection) interface{} {
+		return newer(di)
+	}}
+}
+
+// factoryOf returns the factory registered for type key t, or nil if there is none.
+func (di *DependencyInjection) factoryOf(t reflect.Type) *factory {
+	if v := di.info.view.Load(); v != nil {
+		return v.factories[t]
+	}
+	di.info.mutex.RLock()
+	f := di.info.factories[t]
+	di.info.mutex.RUnlock()
+	return f
+}
+
+// instanceOf returns the object of the Scoped factory f for the container.
+func (di *DependencyInjection) instanceOf(f *factory) *made {
+	di.info.mutex.Lock()
+	if di.info.instances == nil {
+		di.info.instances = make(map[*factory]*made)
+	}
+	inst := di.info.instances[f]
+	if inst == nil {
+		inst = &made{}
+		di.info.instances[f] = inst
+	}
+	di.info.mutex.Unlock()
+	return inst
+}
+
+// produce invokes the factory f registered in owner under type key t0 for a resolution
+// started in resolving, honoring the lifetime of the factory. The factory is built as a
+// construction of t0, so factories depending on each other in a cycle panic with
+// ErrCircularDependency.
+func produce(owner, resolving *DependencyInjection, f *factory, t0 reflect.Type) interface{} {
+	var build = func(di *DependencyInjection) interface{} {
+		dep, err := di.constructAs(t0, func(di *DependencyInjection) (interface{}, error) {
+			dep := f.build(di)
+			if err := initialize(di, dep); err != nil {
+				return nil, err
+			}
+			return dep, nil
+		})
+		if err != nil {
+			panic(err)
+		}
+		return dep
+	}
+
+	switch f.lifetime {
+	case Transient:
+		return build(resolving)
+	case Scoped:
+		return resolving.makeOnce(resolving.instanceOf(f), t0, func(di *DependencyInjection) interface{} {
+			dep := build(di)
+			if dep != nil {
+				resolving.cache(t0, dep)
+			}
+			return dep
+		})
+	default:
+		return owner.along(resolving.path).makeOnce(&f.singleton, t0, func(di *DependencyInjection) interface{} {
+			dep := build(di)
+			if dep != nil {
+				owner.cache(typeKey(dep), dep)
+			}
+			return dep
+		})
+	}
+}
+
+// makeOnce returns the object held by m, or makes it of type key t0 with build, once
+// even for concurrent resolutions within the container. It is kept only if build returns.
+func (di *DependencyInjection) makeOnce(m *made, t0 reflect.Type, build func(di *DependencyInjection) interface{}) interface{} {
+	if dep, ok := m.get(); ok {
+		return dep
+	}
+	dep, err := di.once(m, t0, func(di *DependencyInjection) (interface{}, error) {
+		if dep, ok := m.get(); ok {
+			return dep, nil
+		}
+		dep := build(di)
+		m.mutex.Lock()
+		m.value, m.ok = dep, true
+		m.mutex.Unlock()
+		return dep, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return dep
+}
+
+// get returns the object held by m, if it has been made.
+func (m *made) get() (interface{}, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.value, m.ok
+}
+
+// reset empties m, so the next resolution makes the object again, and returns the
+// object it held, if it had been made.
+func (m *made) reset() (interface{}, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	dep, ok := m.value, m.ok
+	m.value, m.ok = nil, false
+	return dep, ok
+}
+
+// WarmUp invokes every factory registered within the container once, so Singleton and
+// Scoped dependencies are made and cached up front instead of on first resolution.
+// Transient factories are invoked too and their objects discarded. A panicking factory
+// does not stop the others; their panics are returned as errors naming the type, joined.
+func (di *DependencyInjection) WarmUp() error {
+	di.info.mutex.RLock()
+	var types = make([]reflect.Type, 0, len(di.info.factories))
+	for t := range di.info.factories {
+		types = append(types, t)
+	}
+	di.info.mutex.RUnlock()
+
+	sort.Slice(types, func(i, j int) bool {
+		return typeName(types[i]) < typeName(types[j])
+	})
+
+	var errs []error
+	for _, t := range types {
+		if err := di.warmUp(t); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// warmUp invokes the factory registered within the container under type key t0.
+func (di *DependencyInjection) warmUp(t0 reflect.Type) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			if e, ok := v.(*constructionError); ok {
+				err = e
+			} else {
+				err = &constructionError{path: t0.String(), value: v}
+			}
+		}
+	}()
+	if f := di.factoryOf(t0); f != nil {
+		produce(di, di, f, t0)
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package dependency_injection
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type factoryA struct{ b *factoryB }
+type factoryB struct{ a *factoryA }
+
+// panicOf calls fn and returns the value it panicked with, failing the test if fn
+// does not return within a second.
+func panicOf(t *testing.T, fn func()) (v interface{}) {
+	t.Helper()
+	done := make(chan interface{}, 1)
+	go func() {
+		defer func() { done <- recover() }()
+		fn()
+	}()
+	select {
+	case v = <-done:
+		return v
+	case <-time.After(time.Second):
+		t.Fatal("deadlocked")
+		return nil
+	}
+}
+
+func TestFactoryCycleLifetimes(t *testing.T) {
+	type add func(di *DependencyInjection)
+	var lifetimes = map[string]struct{ a, b add }{
+		"AddFactory": {
+			a: func(di *DependencyInjection) {
+				AddFactory(di, func(di *DependencyInjection) *factoryA { return &factoryA{b: MustAny[*factoryB](di)} })
+			},
+			b: func(di *DependencyInjection) {
+				AddFactory(di, func(di *DependencyInjection) *factoryB { return &factoryB{a: MustAny[*factoryA](di)} })
+			},
+		},
+		"AddScoped": {
+			a: func(di *DependencyInjection) {
+				AddScoped(di, func(di *DependencyInjection) *factoryA { return &factoryA{b: MustAny[*factoryB](di)} })
+			},
+			b: func(di *DependencyInjection) {
+				AddScoped(di, func(di *DependencyInjection) *factoryB { return &factoryB{a: MustAny[*factoryA](di)} })
+			},
+		},
+		"AddTransient": {
+			a: func(di *DependencyInjection) {
+				AddTransient(di, func(di *DependencyInjection) *factoryA { return &factoryA{b: MustAny[*factoryB](di)} })
+			},
+			b: func(di *DependencyInjection) {
+				AddTransient(di, func(di *DependencyInjection) *factoryB { return &factoryB{a: MustAny[*factoryA](di)} })
+			},
+		},
+		"AddConstructor": {
+			a: func(di *DependencyInjection) {
+				AddConstructor[*factoryA](di, func(b *factoryB) *factoryA { return &factoryA{b: b} })
+			},
+			b: func(di *DependencyInjection) {
+				AddConstructor[*factoryB](di, func(a *factoryA) *factoryB { return &factoryB{a: a} })
+			},
+		},
+	}
+	for name, lt := range lifetimes {
+		t.Run(name, func(t *testing.T) {
+			di := NewDependencyInjection()
+			lt.a(di)
+			lt.b(di)
+
+			v := panicOf(t, func() { MustAny[*factoryA](di) })
+			err, ok := v.(error)
+			if !ok || !errors.Is(err, ErrCircularDependency) {
+				t.Fatalf("panic = %v, want ErrCircularDependency", v)
+			}
+		})
+	}
+}
+
+func TestFactoryRunsOnce(t *testing.T) {
+	di := NewDependencyInjection()
+
+	var calls int
+	AddFactory(di, func(di *DependencyInjection) *factoryA {
+		calls++
+		return &factoryA{}
+	})
+	first := MustAny[*factoryA](di)
+	if second := MustAny[*factoryA](di); first != second || calls != 1 {
+		t.Fatalf("factory ran %d times, want 1", calls)
+	}
+}
+
+func TestScopedFactoryPerScope(t *testing.T) {
+	di := NewDependencyInjection()
+	AddScoped(di, func(di *DependencyInjection) *factoryA { return &factoryA{} })
+
+	one, two := NewScopedDependencyInjection(di), NewScopedDependencyInjection(di)
+	a1 := MustAny[*factoryA](one)
+	if a1 != MustAny[*factoryA](one) {
+		t.Fatal("Scoped factory made two objects within one scope")
+	}
+	if a1 == MustAny[*factoryA](two) {
+		t.Fatal("Scoped factory shared an object between scopes")
+	}
+}
+
+func TestTransientFactoryEveryResolution(t *testing.T) {
+	di := NewDependencyInjection()
+	AddTransient(di, func(di *DependencyInjection) *factoryA { return &factoryA{} })
+
+	if MustAny[*factoryA](di) == MustAny[*factoryA](di) {
+		t.Fatal("Transient factory reused an object")
+	}
+}
+
+func TestFactoryRetriedAfterPanic(t *testing.T) {
+	for name, add := range map[string]func(*DependencyInjection, func(*DependencyInjection) *factoryA){
+		"AddSingleton": AddSingleton[*factoryA],
+		"AddScoped":    AddScoped[*factoryA],
+	} {
+		t.Run(name, func(t *testing.T) {
+			di := NewDependencyInjection()
+
+			var calls int
+			add(di, func(*DependencyInjection) *factoryA {
+				calls++
+				if calls == 1 {
+					panic("database not ready")
+				}
+				return &factoryA{}
+			})
+			if v := panicOf(t, func() { MustAny[*factoryA](di) }); v == nil {
+				t.Fatal("first resolution did not panic")
+			}
+			if _, ok := TryAny[*factoryA](di); !ok || calls != 2 {
+				t.Fatalf("resolution after a panic: ok = %v after %d calls, want the factory retried", ok, calls)
+			}
+		})
+	}
+}
+
+func TestWarmUpRetriedAfterPanic(t *testing.T) {
+	di := NewDependencyInjection()
+
+	var fail = true
+	AddSingleton(di, func(*DependencyInjection) *factoryA {
+		if fail {
+			panic("database not ready")
+		}
+		return &factoryA{}
+	})
+	if err := di.WarmUp(); err == nil {
+		t.Fatal("WarmUp with a panicking factory returned nil")
+	}
+	fail = false
+	if err := di.WarmUp(); err != nil {
+		t.Fatalf("second WarmUp = %v, want the factory retried", err)
+	}
+}
+
+func TestFactoryConcurrentCycle(t *testing.T) {
+	di := NewDependencyInjection()
+
+	var building sync.WaitGroup
+	building.Add(2)
+	AddSingleton(di, func(di *DependencyInjection) *factoryA {
+		building.Done()
+		building.Wait()
+		return &factoryA{b: MustAny[*factoryB](di)}
+	})
+	AddSingleton(di, func(di *DependencyInjection) *factoryB {
+		building.Done()
+		building.Wait()
+		return &factoryB{a: MustAny[*factoryA](di)}
+	})
+
+	var panics = make(chan interface{}, 2)
+	go func() {
+		defer func() { panics <- recover() }()
+		MustAny[*factoryA](di)
+	}()
+	go func() {
+		defer func() { panics <- recover() }()
+		MustAny[*factoryB](di)
+	}()
+	for i := 0; i < 2; i++ {
+		select {
+		case v := <-panics:
+			if err, ok := v.(error); !ok || !errors.Is(err, ErrCircularDependency) {
+				t.Fatalf("panic = %v, want ErrCircularDependency", v)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("factories waiting on each other across goroutines deadlocked")
+		}
+	}
+}
+
+func TestFactoryConcurrentRunsOnce(t *testing.T) {
+	di := NewDependencyInjection()
+
+	var calls int
+	var mutex sync.Mutex
+	AddSingleton(di, func(*DependencyInjection) *factoryA {
+		mutex.Lock()
+		calls++
+		mutex.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		return &factoryA{}
+	})
+
+	var wg sync.WaitGroup
+	var got [50]*factoryA
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i] = MustAny[*factoryA](di)
+		}(i)
+	}
+	wg.Wait()
+	for i := range got {
+		if got[i] != got[0] {
+			t.Fatal("concurrent resolutions got different objects")
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("factory ran %d times, want 1", calls)
+	}
+}
+
+func TestLifetimesMixed(t *testing.T) {
+	di := NewDependencyInjection()
+	AddSingleton(di, func(*DependencyInjection) *factoryA { return &factoryA{} })
+	AddScoped(di, func(*DependencyInjection) *factoryB { return &factoryB{} })
+	AddTransient(di, func(*DependencyInjection) *testConfig { return &testConfig{} })
+
+	one, two := NewScopedDependencyInjection(di), NewScopedDependencyInjection(di)
+	if MustAny[*factoryA](one) != MustAny[*factoryA](two) {
+		t.Fatal("Singleton factory made an object per scope")
+	}
+	if MustAny[*factoryB](one) != MustAny[*factoryB](one) || MustAny[*factoryB](one) == MustAny[*factoryB](two) {
+		t.Fatal("Scoped factory did not make exactly one object per scope")
+	}
+	if MustAny[*testConfig](one) == MustAny[*testConfig](one) {
+		t.Fatal("Transient factory reused an object within a scope")
+	}
+}
+
+func TestWarmUpNamesFailingFactory(t *testing.T) {
+	di := NewDependencyInjection()
+	var built bool
+	AddSingleton(di, func(*DependencyInjection) *factoryA { panic("database not reachable") })
+	AddSingleton(di, func(*DependencyInjection) *factoryB {
+		built = true
+		return &factoryB{}
+	})
+
+	err := di.WarmUp()
+	if err == nil || !strings.Contains(err.Error(), "factoryA") || !strings.Contains(err.Error(), "database not reachable") {
+		t.Fatalf("WarmUp = %v, want an error naming *factoryA", err)
+	}
+	if !built || !Has[*factoryB](di) {
+		t.Fatal("WarmUp did not construct the other factory")
+	}
+}
@@ -0,0 +1,88 @@
+package dependency_injection
+
+import (
+	"reflect"
+)
+
+// Federate makes resolutions that miss in the container and its parents fall back to
+// the others, in order, before reporting ErrDependencyNotFound. Unlike a parent, a
+// federated container is an independent peer: it resolves through its own parents and
+// federation, makes the objects of its factories within itself, and is tried at most
+// once per resolution, so containers federating each other in a cycle resolve a missing
+// type without looping. Children of the container fall back to its federation as well.
+// Has(...), All(...), RequireAll(...), Validate() and Describe(...) consult the federation
+// like Any(...); names, keys and groups, e.g. Named(...) and GroupOf(...), do not.
+// Federating the container itself or nil does nothing.
+func (di *DependencyInjection) Federate(others ...*DependencyInjection) {
+	di.info.mutex.Lock()
+	for _, other := range others {
+		if other != nil && other.info != di.info {
+			di.info.federated = append(di.info.federated[:len(di.info.federated):len(di.info.federated)], other)
+		}
+	}
+	di.info.publish()
+	di.info.mutex.Unlock()
+}
+
+// federation returns the containers federated by the container.
+func (di *DependencyInjection) federation() []*DependencyInjection {
+	if v := di.info.view.Load(); v != nil {
+		return v.federated
+	}
+	di.info.mutex.RLock()
+	federated := di.info.federated
+	di.info.mutex.RUnlock()
+	return federated
+}
+
+// federate resolves a dependency of type key t0 or satisfying match from the containers
+// federated by the first n containers of the chain of the container and its parents, in
+// order, skipping those in visited, and returns it with the level of the container
+// federating the one providing it.
+func (di *DependencyInjection) federate(n int, t0 reflect.Type, match func(dep interface{}) bool,
+	visited *visitedSet) (interface{}, int, bool) {
+	var c = di
+	for level := 0; level < n; level, c = level+1, c.Parent() {
+		for _, peer := range c.federation() {
+			if dep, _, ok := peer.along(di.path).resolveVisiting(t0, match, visited); ok {
+				return dep, level, true
+			}
+		}
+	}
+	return nil, 0, false
+}
+
+// walk calls f for the container and its parents, then for the containers they federate
+// and theirs in turn, in the order resolution tries them, each once, until f returns false,
+// and reports whether it never did. Besides each container, f receives its level: its
+// number of parents from di, or for a federated container the level of the one federating it.
+func (di *DependencyInjection) walk(f func(c *DependencyInjection, level int, federated bool) bool) bool {
+	var visited visitedSet
+	return di.walkVisiting(&visited, -1, f)
+}
+
+// walkVisiting walks the containers like walk, skipping those in visited and adding those
+// it walks, and passes f the level at instead of their own when at is not negative.
+func (di *DependencyInjection) walkVisiting(visited *visitedSet, at int,
+	f func(c *DependencyInjection, level int, federated bool) bool) bool {
+	var n int
+	for c := di; c != nil && visited.add(c.info); c = c.Parent() {
+		if at < 0 && !f(c, n, false) || at >= 0 && !f(c, at, true) {
+			return false
+		}
+		n++
+	}
+	var c = di
+	for i := 0; i < n; i, c = i+1, c.Parent() {
+		var level = i
+		if at >= 0 {
+			level = at
+		}
+		for _, peer := range c.federation() {
+			if !peer.walkVisiting(visited, level, f) {
+				return false
+			}
+		}
+	}
+	return true
+}
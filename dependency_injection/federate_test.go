@@ -0,0 +1,139 @@
+package dependency_injection
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFederateFallsBackToPeers(t *testing.T) {
+	primary, first, second := NewDependencyInjection(), NewDependencyInjection(), NewDependencyInjection()
+	config := &testConfig{name: "primary"}
+	primary.Add(config)
+	first.Add(&testConfig{name: "first"})
+	english := &testEnglish{accent: "second"}
+	second.Add(english)
+	second.Add(&testConfig{name: "second"})
+	primary.Federate(first, second)
+
+	if got := MustAny[*testConfig](primary); got != config {
+		t.Fatalf("federating container resolved %v, want its own config", got)
+	}
+	if got := MustAny[*testEnglish](primary); got != english {
+		t.Fatalf("type present only in a peer resolved to %v, want the peer's", got)
+	}
+	if got := MustAny[testGreeter](primary); got != english {
+		t.Fatalf("interface present only in a peer resolved to %v, want the peer's", got)
+	}
+
+	child := NewChild(primary)
+	if got, level, err := AnyFrom[*testEnglish](child); err != nil || got != english || level != 1 {
+		t.Fatalf("child of a federating container resolved %v at level %d, %v; want the peer's through the parent", got, level, err)
+	}
+	if got, err := AnyContext[*testEnglish](context.Background(), child); err != nil || got != english {
+		t.Fatalf("AnyContext through a federation = %v, %v", got, err)
+	}
+	if _, ok := TryAny[*testEnglish](first); ok {
+		t.Fatal("federation made the peers resolve from each other")
+	}
+}
+
+func TestFederateCycle(t *testing.T) {
+	a, b := NewDependencyInjection(), NewDependencyInjection()
+	a.Federate(b, a)
+	b.Federate(a)
+	b.Add(&testConfig{name: "b"})
+
+	if got := MustAny[*testConfig](a); got.name != "b" {
+		t.Fatalf("a resolved %v, want the config of b", got)
+	}
+	var english *testEnglish
+	if err := panicOf(t, func() { _ = Any(a, &english) }); err != nil {
+		t.Fatalf("Any of a missing type in a federation cycle panicked with %v", err)
+	}
+	if err := Any(b, &english); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("Any of a missing type in a federation cycle = %v, want ErrDependencyNotFound", err)
+	}
+}
+
+func TestFederateInspection(t *testing.T) {
+	a, b := NewDependencyInjection(), NewDependencyInjection()
+	a.Federate(b)
+	english := &testEnglish{accent: "b"}
+	b.Add(english)
+	AddFactory(b, func(*DependencyInjection) *testConfig { return &testConfig{name: "b"} })
+	a.AddNamed("name", &testPerson{})
+	b.AddNamed("peer", testRegion(1))
+
+	if !Has[*testEnglish](a) || !Has[*testConfig](a) {
+		t.Fatal("Has did not find the registration and the factory of the peer")
+	}
+	if got := All[testGreeter](a); len(got) != 1 || got[0] != english {
+		t.Fatalf("All through the federation = %v, want the dependency of the peer", got)
+	}
+	if err := a.RequireAll(&testEnglish{}, &testConfig{}); err != nil {
+		t.Fatalf("RequireAll of types resolvable through the federation = %v", err)
+	}
+	if err := a.RequireAll(&testPerson{}); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("RequireAll of a type only named = %v, want ErrDependencyNotFound", err)
+	}
+	want := Registration{Type: "*github.com/martinarisk/di/dependency_injection.testConfig", Factory: true, Federated: true}
+	wantChild := want
+	wantChild.Level = 1
+	if got, ok := Describe[*testConfig](NewChild(a)); !ok || got != wantChild {
+		t.Fatalf("Describe through the federation of the parent = %+v, %v", got, ok)
+	}
+	if got, ok := Describe[*testConfig](a); !ok || got != want {
+		t.Fatalf("Describe through the federation = %+v, %v; want %+v", got, ok, want)
+	}
+	if _, err := Named[testRegion](a, "peer"); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("Named through the federation = %v, want names kept out of it", err)
+	}
+}
+
+func TestFederatePeerIsChild(t *testing.T) {
+	parent := NewDependencyInjection()
+	child := NewChild(parent)
+	parent.Federate(child)
+	english := &testEnglish{accent: "child"}
+	child.Add(english)
+
+	if got, level, err := AnyFrom[*testEnglish](parent); err != nil || got != english || level != 0 {
+		t.Fatalf("parent resolved %v at level %d, %v; want the dependency of its federated child", got, level, err)
+	}
+	for name, di := range map[string]*DependencyInjection{"parent": parent, "child": child} {
+		var config *testConfig
+		if err := panicOf(t, func() { _ = Any(di, &config) }); err != nil {
+			t.Fatalf("%s Any of a missing type panicked with %v", name, err)
+		}
+		if err := Any(di, &config); !errors.Is(err, ErrDependencyNotFound) {
+			t.Fatalf("%s Any of a missing type = %v, want ErrDependencyNotFound", name, err)
+		}
+		if Has[*testConfig](di) || len(All[*testConfig](di)) != 0 {
+			t.Fatalf("%s found a missing type", name)
+		}
+	}
+}
+
+func TestFederateSkipsVisitedParent(t *testing.T) {
+	root := NewDependencyInjection()
+	shared := NewDependencyInjection()
+	root.Federate(shared)
+	config := &testConfig{name: "shared"}
+	shared.Add(config)
+
+	a, b := NewChild(root), NewChild(root)
+	a.Federate(b)
+	english := &testEnglish{accent: "b"}
+	b.Add(english)
+
+	if got, level, err := AnyFrom[*testEnglish](a); err != nil || got != english || level != 0 {
+		t.Fatalf("a resolved %v at level %d, %v; want the dependency of its peer", got, level, err)
+	}
+	if got, level, err := AnyFrom[*testConfig](a); err != nil || got != config || level != 1 {
+		t.Fatalf("a resolved %v at level %d, %v; want the dependency federated by the root at level 1", got, level, err)
+	}
+	if got := All[*testConfig](a); len(got) != 1 {
+		t.Fatalf("All through a peer sharing the parent = %v, want the shared dependency once", got)
+	}
+}
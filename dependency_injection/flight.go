@@ -0,0 +1,141 @@
+package dependency_injection
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// flight is a construction of a dependency in progress, which concurrent requests
+// for the same type wait for instead of constructing their own.
+type flight struct {
+	t     reflect.Type
+	node  *resolution
+	done  chan struct{}
+	value interface{}
+	err   error
+	panic interface{}
+}
+
+// waits records the flight each resolution blocked in once is waiting for, keyed by
+// the innermost construction of the resolution, so that waiting in a cycle across
+// goroutines is reported instead of deadlocking.
+var waits = struct {
+	flights map[*resolution]*flight
+	mutex   sync.Mutex
+}{flights: make(map[*resolution]*flight)}
+
+// once runs fn, the construction of type key t0 identified by key, unless it is already
+// in flight within the container, in which case it waits for it and shares its outcome.
+// fn receives a handle whose resolution marks the flight, so constructions started by fn
+// are known to be part of it. A construction requesting key again runs fn directly, so the
+// cycle is reported, as is waiting for a flight that is itself waiting for this resolution.
+func (di *DependencyInjection) once(key interface{}, t0 reflect.Type, fn func(di *DependencyInjection) (interface{}, error)) (interface{}, error) {
+	di.info.mutex.Lock()
+	if f := di.info.flights[key]; f != nil {
+		di.info.mutex.Unlock()
+		if di.path.within(f.node) {
+			return fn(di)
+		}
+		return f.wait(di.path)
+	}
+	if di.info.flights == nil {
+		di.info.flights = make(map[interface{}]*flight)
+	}
+	f := &flight{t: t0, done: make(chan struct{})}
+	f.node = &resolution{t: t0, parent: di.path, flight: f}
+	if di.path != nil {
+		f.node.depth = di.path.depth
+	}
+	di.info.flights[key] = f
+	di.info.mutex.Unlock()
+
+	defer func() {
+		f.panic = recover()
+
+		di.info.mutex.Lock()
+		delete(di.info.flights, key)
+		di.info.mutex.Unlock()
+
+		close(f.done)
+		if f.panic != nil {
+			panic(f.panic)
+		}
+	}()
+	f.value, f.err = fn(di.along(f.node))
+	return f.value, f.err
+}
+
+// wait blocks until the flight lands and returns its outcome on behalf of the
+// resolution r, or returns ErrCircularDependency if the flight waits for r.
+func (f *flight) wait(r *resolution) (interface{}, error) {
+	if r != nil {
+		waits.mutex.Lock()
+		if path, ok := f.cycle(r); ok {
+			waits.mutex.Unlock()
+			return nil, &constructionError{path: path, value: ErrCircularDependency}
+		}
+		waits.flights[r] = f
+		waits.mutex.Unlock()
+
+		defer func() {
+			waits.mutex.Lock()
+			delete(waits.flights, r)
+			waits.mutex.Unlock()
+		}()
+	}
+
+	<-f.done
+	if f.panic != nil {
+		panic(f.panic)
+	}
+	return f.value, f.err
+}
+
+// cycle reports whether the flight, directly or through the flights it waits for,
+// waits for a flight begun along the resolution r, and returns the types involved.
+// It must be called with waits locked.
+func (f *flight) cycle(r *resolution) (string, bool) {
+	var names []string
+	if path := r.String(); path != "" {
+		names = append(names, path)
+	}
+	var seen = make(map[*flight]bool)
+	for f != nil && !seen[f] {
+		seen[f] = true
+		names = append(names, f.t.String())
+		if r.within(f.node) {
+			return strings.Join(names, " -> "), true
+		}
+		var next *flight
+		for waiter, g := range waits.flights {
+			if waiter.within(f.node) {
+				next = g
+				break
+			}
+		}
+		f = next
+	}
+	return "", false
+}
+
+// within reports whether the resolution r continues the resolution node.
+func (r *resolution) within(node *resolution) bool {
+	for ; r != nil; r = r.parent {
+		if r == node {
+			return true
+		}
+	}
+	return false
+}
+
+// building reports whether type t is under construction along the resolution r.
+func (r *resolution) building(t reflect.Type) bool {
+	for ; r != nil; r = r.parent {
+		if r.t == t && r.flight == nil && atomic.LoadInt32(&r.done) == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package dependency_injection
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type flightA struct{ b flightB }
+type flightB struct{ a *flightA }
+
+func TestMustNeedConcurrentSharesObject(t *testing.T) {
+	di := NewDependencyInjection()
+
+	var calls int
+	var mutex sync.Mutex
+	start := make(chan struct{})
+	newer := func(*DependencyInjection) **flightA {
+		mutex.Lock()
+		calls++
+		mutex.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		a := &flightA{}
+		return &a
+	}
+
+	var wg sync.WaitGroup
+	var got [100]*flightA
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			got[i] = MustNeed(di, newer)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("constructor ran %d times, want 1", calls)
+	}
+	for i := range got {
+		if got[i] != got[0] {
+			t.Fatalf("goroutine %d got %p, want the identical pointer %p", i, got[i], got[0])
+		}
+	}
+}
+
+func TestMustNeedCrossGoroutineCycle(t *testing.T) {
+	di := NewDependencyInjection()
+
+	var building sync.WaitGroup
+	building.Add(2)
+	newA := func(di *DependencyInjection) *flightA {
+		building.Done()
+		building.Wait()
+		return &flightA{b: MustNeed(di, func(*DependencyInjection) *flightB { return &flightB{} })}
+	}
+	newB := func(di *DependencyInjection) *flightB {
+		building.Done()
+		building.Wait()
+		return &flightB{a: Ptr(MustNeed(di, func(*DependencyInjection) *flightA { return &flightA{} }))}
+	}
+
+	var panics = make(chan interface{}, 2)
+	for _, fn := range []func(){
+		func() { MustNeed(di, newA) },
+		func() { MustNeed(di, newB) },
+	} {
+		go func(fn func()) {
+			defer func() { panics <- recover() }()
+			fn()
+		}(fn)
+	}
+
+	var cycles int
+	for i := 0; i < 2; i++ {
+		select {
+		case v := <-panics:
+			if err, ok := v.(error); ok && errors.Is(err, ErrCircularDependency) {
+				cycles++
+			}
+		case <-time.After(time.Second):
+			t.Fatal("constructors waiting on each other across goroutines deadlocked")
+		}
+	}
+	if cycles == 0 {
+		t.Fatal("no resolution reported ErrCircularDependency")
+	}
+}
+
+func TestMustNeedSameGoroutineCycle(t *testing.T) {
+	di := NewDependencyInjection()
+
+	var newA func(di *DependencyInjection) *flightA
+	newA = func(di *DependencyInjection) *flightA {
+		return &flightA{b: MustNeed(di, func(di *DependencyInjection) *flightB {
+			return &flightB{a: Ptr(MustNeed(di, newA))}
+		})}
+	}
+	v := panicOf(t, func() { MustNeed(di, newA) })
+	if err, ok := v.(error); !ok || !errors.Is(err, ErrCircularDependency) {
+		t.Fatalf("panic = %v, want ErrCircularDependency", v)
+	}
+}
+
+func TestNeedConcurrentFailureNotCached(t *testing.T) {
+	di := NewDependencyInjection()
+
+	var fail = true
+	newer := func(*DependencyInjection) (*flightA, error) {
+		if fail {
+			return nil, errors.New("not yet")
+		}
+		return &flightA{}, nil
+	}
+	if _, err := Need(di, newer); err == nil {
+		t.Fatal("Need did not return the constructor error")
+	}
+	fail = false
+	if _, err := Need(di, newer); err != nil {
+		t.Fatalf("Need after a failure = %v, want success", err)
+	}
+}
@@ -0,0 +1,52 @@
+package dependency_injection
+
+// AddToGroup adds a dependency to the given group, whatever its type. Groups live in
+// their own keyspace, separate from the type-keyed and named dependencies, and keep
+// the order in which their members were added. Adding a member again moves it to the end.
+func (di *DependencyInjection) AddToGroup(group string, dep interface{}) {
+	checkIdentity(dep)
+
+	di.info.mutex.Lock()
+
+	if !di.info.writable() || dep == nil {
+		di.info.mutex.Unlock()
+		return
+	}
+
+	if di.info.groups == nil {
+		di.info.groups = make(map[string][]interface{})
+	}
+	var members = di.info.groups[group]
+	for i := range members {
+		if identity(members[i]) == identity(dep) {
+			members = append(members[:i:i], members[i+1:]...)
+			break
+		}
+	}
+	di.info.groups[group] = append(members, dep)
+
+	di.info.mutex.Unlock()
+}
+
+// GroupOf retrieves the members of the given group in the container, in the order they
+// were added, followed by those of the group in the parent containers.
+func GroupOf(di *DependencyInjection, group string) (members []interface{}) {
+	var visited visitedSet
+	for ; di != nil && visited.add(di.info); di = di.Parent() {
+		di.info.mutex.RLock()
+		members = append(members, di.info.groups[group]...)
+		di.info.mutex.RUnlock()
+	}
+	return
+}
+
+// GroupOfType retrieves the members of the given group that are of type T, in the
+// order of GroupOf(...).
+func GroupOfType[T any](di *DependencyInjection, group string) (results []T) {
+	for _, dep := range GroupOf(di, group) {
+		if result, ok := (dep).(T); ok {
+			results = append(results, result)
+		}
+	}
+	return
+}
@@ -0,0 +1,36 @@
+package dependency_injection
+
+import "testing"
+
+func TestGroupMixedTypes(t *testing.T) {
+	parent := NewDependencyInjection()
+	config, english, port := &testConfig{name: "app"}, &testEnglish{}, 8080
+	parent.AddToGroup("startup", config)
+	parent.AddToGroup("startup", english)
+	child := NewChild(parent)
+	child.AddToGroup("startup", port)
+	parent.AddToGroup("shutdown", &testPerson{})
+
+	members := GroupOf(child, "startup")
+	if len(members) != 3 || members[0] != port || members[1] != config || members[2] != english {
+		t.Fatalf("GroupOf = %v, want the child's member and then the parent's in order", members)
+	}
+	if greeters := GroupOfType[testGreeter](child, "startup"); len(greeters) != 1 || greeters[0] != english {
+		t.Fatalf("GroupOfType[testGreeter] = %v, want the *testEnglish only", greeters)
+	}
+	if _, ok := TryAny[*testConfig](parent); ok {
+		t.Fatal("group member resolved as a type-keyed dependency")
+	}
+}
+
+func TestGroupReAddMovesToEnd(t *testing.T) {
+	di := NewDependencyInjection()
+	a, b := &testConfig{name: "a"}, &testConfig{name: "b"}
+	di.AddToGroup("g", a)
+	di.AddToGroup("g", b)
+	di.AddToGroup("g", a)
+
+	if members := GroupOf(di, "g"); len(members) != 2 || members[0] != b || members[1] != a {
+		t.Fatalf("GroupOf = %v, want b then a", members)
+	}
+}
@@ -0,0 +1,52 @@
+package dependency_injection
+
+import (
+	"reflect"
+)
+
+// OnResolve registers a hook that Any(...) calls after each lookup with the requested
+// type key and whether a dependency was found. Hooks of the container and of its parents
+// are all called, the container's own first, each in registration order, and never
+// while the container is locked, so a hook may resolve dependencies itself.
+func (di *DependencyInjection) OnResolve(hook func(typeName string, found bool)) {
+	di.info.mutex.Lock()
+	di.info.hooks = append(di.info.hooks, hook)
+	di.info.publish()
+	di.info.mutex.Unlock()
+}
+
+// resolved records a lookup by Any(...) in the counters of the container and calls
+// the resolve hooks of the container and its parents.
+func (di *DependencyInjection) resolved(t0 reflect.Type, found bool) {
+	if di != nil && found {
+		di.info.hits.Add(1)
+	} else if di != nil {
+		di.info.misses.Add(1)
+	}
+	if di != nil && di.info.debug.Load() != nil {
+		if found {
+			di.info.debugf("resolve %v hit", t0)
+		} else {
+			di.info.debugf("resolve %v miss", t0)
+		}
+	}
+
+	var hooks []func(typeName string, found bool)
+	var visited visitedSet
+	for c := di; c != nil && visited.add(c.info); c = c.Parent() {
+		if v := c.info.view.Load(); v != nil {
+			hooks = append(hooks, v.hooks...)
+			continue
+		}
+		c.info.mutex.RLock()
+		hooks = append(hooks, c.info.hooks...)
+		c.info.mutex.RUnlock()
+	}
+	if len(hooks) == 0 {
+		return
+	}
+	var name = t0.String()
+	for _, hook := range hooks {
+		hook(name, found)
+	}
+}
@@ -0,0 +1,137 @@
+package dependency_injection
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type identityBag struct {
+	items []string
+}
+
+func TestIdentityFunctions(t *testing.T) {
+	di := NewDependencyInjection()
+
+	var calls int
+	f := func() { calls++ }
+	g := func() { calls++ }
+	di.Add(f)
+	di.Add(f)
+	di.Add(g)
+
+	if n := len(All[func()](di)); n != 2 {
+		t.Fatalf("All = %d functions, want 2 distinct closures", n)
+	}
+	if !di.ContainsInstance(f) {
+		t.Fatal("ContainsInstance(f) = false for a registered function")
+	}
+	di.Remove(f)
+	if di.ContainsInstance(f) || !di.ContainsInstance(g) {
+		t.Fatal("Remove(f) did not remove exactly f")
+	}
+}
+
+func TestIdentityClosuresOfOneLiteral(t *testing.T) {
+	di := NewDependencyInjection()
+
+	var counter = func(n int) func() int { return func() int { return n } }
+	one, two := counter(1), counter(2)
+	di.Add(one)
+	di.Add(two)
+
+	var sum int
+	for _, f := range All[func() int](di) {
+		sum += f()
+	}
+	if sum != 3 {
+		t.Fatalf("closures sum to %d, want both registered", sum)
+	}
+	di.Remove(one)
+	if di.ContainsInstance(one) || !di.ContainsInstance(two) {
+		t.Fatal("Remove(one) did not remove exactly one")
+	}
+}
+
+func TestIdentityMaps(t *testing.T) {
+	di := NewDependencyInjection()
+
+	m := map[string]int{"a": 1}
+	di.Add(m)
+	di.Add(m)
+	di.Add(map[string]int{"a": 1})
+
+	if n := len(All[map[string]int](di)); n != 2 {
+		t.Fatalf("All = %d maps, want 2", n)
+	}
+	if !di.ContainsInstance(m) {
+		t.Fatal("ContainsInstance(m) = false for a registered map")
+	}
+	di.Remove(m)
+	if di.ContainsInstance(m) {
+		t.Fatal("Remove(m) left the map registered")
+	}
+}
+
+func TestIdentityRefusesSlices(t *testing.T) {
+	for name, dep := range map[string]interface{}{
+		"slice":  []string{"a"},
+		"struct": identityBag{items: []string{"a"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			di := NewDependencyInjection()
+
+			v := panicOf(t, func() { di.Add(dep) })
+			if err, ok := v.(error); !ok || !errors.Is(err, ErrNotComparable) {
+				t.Fatalf("Add panicked with %v, want ErrNotComparable", v)
+			}
+			if di.ContainsInstance(dep) {
+				t.Fatal("ContainsInstance = true for a refused value")
+			}
+			di.Remove(dep)
+		})
+	}
+}
+
+func TestIdentityPointerToSlice(t *testing.T) {
+	di := NewDependencyInjection()
+
+	s := &[]string{"a"}
+	di.Add(s)
+	di.Add(s)
+	if n := len(All[*[]string](di)); n != 1 || !di.ContainsInstance(s) {
+		t.Fatalf("All = %d, want the pointer registered once", n)
+	}
+}
+
+func TestIdentityComparableValues(t *testing.T) {
+	di := NewDependencyInjection()
+
+	di.Add(1)
+	di.Add(1)
+	di.Add(2)
+	if n := len(All[int](di)); n != 2 {
+		t.Fatalf("All = %d ints, want 2", n)
+	}
+}
+
+func TestFunctionsKeyedBySignature(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(func(ctx context.Context) error { return errors.New("run") })
+	di.Add(func(name string) string { return "hello " + name })
+	di.Add(func(a, b int) int { return a + b })
+
+	run := MustAny[func(context.Context) error](di)
+	if err := run(context.Background()); err == nil || err.Error() != "run" {
+		t.Fatalf("func(context.Context) error = %v, want the registered function", err)
+	}
+	if got := MustAny[func(string) string](di)("gopher"); got != "hello gopher" {
+		t.Fatalf("func(string) string = %q", got)
+	}
+	if got := MustAny[func(int, int) int](di)(2, 3); got != 5 {
+		t.Fatalf("func(int, int) int = %d", got)
+	}
+	if _, ok := TryAny[func(int) int](di); ok {
+		t.Fatal("resolved a signature that was never registered")
+	}
+}
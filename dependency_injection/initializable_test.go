@@ -0,0 +1,92 @@
+package dependency_injection
+
+import (
+	"errors"
+	"testing"
+)
+
+type testResource struct {
+	open  bool
+	inits *int
+	fail  error
+}
+
+func (r *testResource) Init(di *DependencyInjection) error {
+	*r.inits++
+	if r.fail != nil {
+		return r.fail
+	}
+	r.open = true
+	return nil
+}
+
+func TestInitCalledOnceForSingleton(t *testing.T) {
+	di := NewDependencyInjection()
+	var inits int
+	newer := func(*DependencyInjection) *testResource { return &testResource{inits: &inits} }
+
+	first := MustNeed(di, newer)
+	second := MustNeed(di, newer)
+	if !first.open || !second.open {
+		t.Fatal("MustNeed returned a resource that Init did not open")
+	}
+	if inits != 1 {
+		t.Fatalf("Init called %d times for a singleton, want 1", inits)
+	}
+}
+
+func TestInitErrorFailsConstruction(t *testing.T) {
+	di := NewDependencyInjection()
+	var inits int
+	failed := errors.New("open failed")
+	_, err := Need(di, func(*DependencyInjection) (*testResource, error) {
+		return &testResource{inits: &inits, fail: failed}, nil
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Need = %v, want the Init error", err)
+	}
+	if _, ok := TryAny[testResource](di); ok {
+		t.Fatal("Need cached a resource whose Init failed")
+	}
+
+	if _, err := Build[*testResource](di, func() *testResource {
+		return &testResource{inits: &inits, fail: failed}
+	}); !errors.Is(err, failed) {
+		t.Fatalf("Build = %v, want the Init error", err)
+	}
+}
+
+func TestInitCalledForFactoryAndBuild(t *testing.T) {
+	di := NewDependencyInjection()
+	var inits int
+	AddFactory(di, func(*DependencyInjection) *testResource { return &testResource{inits: &inits} })
+	if got := MustAny[*testResource](di); !got.open {
+		t.Fatal("factory resource not initialized")
+	}
+	MustAny[*testResource](di)
+	if inits != 1 {
+		t.Fatalf("Init called %d times for a factory singleton, want 1", inits)
+	}
+
+	var built int
+	if err := AddConstructor[*lifetimeCounter](di, func(r *testResource) *lifetimeCounter { return &lifetimeCounter{} }); err != nil {
+		t.Fatal(err)
+	}
+	MustAny[*lifetimeCounter](di)
+	got, err := Build[*testResource](di, func() *testResource { return &testResource{inits: &built} })
+	if err != nil || !got.open || built != 1 {
+		t.Fatalf("Build = %v, %v with %d Init calls, want one initialized resource", got, err, built)
+	}
+	if inits != 1 {
+		t.Fatalf("resolving a constructor parameter initialized it again: %d calls", inits)
+	}
+
+	var constructed int
+	other := NewDependencyInjection()
+	if err := AddConstructor[*testResource](other, func() *testResource { return &testResource{inits: &constructed} }); err != nil {
+		t.Fatal(err)
+	}
+	if got := MustAny[*testResource](other); !got.open || constructed != 1 {
+		t.Fatalf("AddConstructor resource = %v after %d Init calls, want it initialized once", got, constructed)
+	}
+}
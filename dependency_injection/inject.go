@@ -0,0 +1,163 @@
+package dependency_injection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTarget is returned by Inject(...) when the target is not a non-nil pointer to a struct.
+var ErrInvalidTarget = errors.New("target must be a non-nil pointer to a struct")
+
+// ErrInvalidTag is matched by the error of Inject(...) for a `di` tag it cannot apply,
+// such as an unknown option or a default that does not parse as the type of its field.
+var ErrInvalidTag = errors.New("invalid di tag")
+
+// Inject sets each exported field of the struct pointed to by target that has a `di` tag
+// to the dependency of the field's type resolved from the container; see InjectAll(...)
+// to inject untagged fields too. The tag holds options separated by commas:
+//
+//   - `di:""` resolves the field by its type;
+//   - `di:"name=primary"` resolves the dependency registered with AddNamed under that name;
+//   - `di:"optional"` leaves the field untouched when nothing resolves;
+//   - `di:"default=8080"` sets a string, bool or numeric field, including a time.Duration,
+//     to the value when nothing resolves; it takes the rest of the tag, commas included,
+//     so it must come last;
+//   - `di:"-"` skips the field.
+//
+// Any other unresolved field, and any tag that cannot be applied, is reported in the
+// returned error, naming the field and its type.
+func Inject(di *DependencyInjection, target interface{}) error {
+	return inject(di, target, false)
+}
+
+// InjectAll sets the fields of the struct pointed to by target like Inject(...), and
+// also resolves every exported field without a `di` tag by its type.
+func InjectAll(di *DependencyInjection, target interface{}) error {
+	return inject(di, target, true)
+}
+
+// inject sets the fields of target as documented by Inject, including the untagged ones
+// when all is set.
+func inject(di *DependencyInjection, target interface{}, all bool) error {
+	var v = reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ErrInvalidTarget
+	}
+	v = v.Elem()
+
+	var errs []error
+	for i := 0; i < v.NumField(); i++ {
+		var field = v.Type().Field(i)
+		var raw, tagged = field.Tag.Lookup("di")
+		if !field.IsExported() || (!tagged && !all) {
+			continue
+		}
+		tag, err := parseTag(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: field %s: %v", ErrInvalidTag, field.Name, err))
+			continue
+		}
+		if tag.skip {
+			continue
+		}
+
+		var dep interface{}
+		var ok bool
+		if tag.named {
+			if dep, err = di.named(tag.name, field.Type); err != nil && !errors.Is(err, ErrDependencyNotFound) {
+				errs = append(errs, fmt.Errorf("field %s: %w", field.Name, err))
+				continue
+			}
+			ok = err == nil
+		} else {
+			dep, ok = di.resolve(field.Type, isType(field.Type))
+		}
+		switch {
+		case ok:
+			v.Field(i).Set(reflect.ValueOf(dep))
+		case tag.defaulted:
+			if err := setDefault(v.Field(i), tag.value); err != nil {
+				errs = append(errs, fmt.Errorf("%w: field %s of type %s: %v", ErrInvalidTag, field.Name, field.Type, err))
+			}
+		case !tag.optional:
+			errs = append(errs, fmt.Errorf("%w: field %s of type %s", ErrDependencyNotFound, field.Name, field.Type))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// injectTag holds the options of a `di` struct tag, see Inject.
+type injectTag struct {
+	skip, optional   bool
+	named, defaulted bool
+	name, value      string
+}
+
+// parseTag parses the options of a `di` struct tag.
+func parseTag(raw string) (t injectTag, err error) {
+	if raw == "-" {
+		t.skip = true
+		return
+	}
+	for raw != "" {
+		var opt string
+		if strings.HasPrefix(raw, "default=") {
+			opt, raw = raw, ""
+		} else {
+			opt, raw, _ = strings.Cut(raw, ",")
+		}
+		switch key, value, _ := strings.Cut(opt, "="); key {
+		case "optional":
+			t.optional = true
+		case "name":
+			t.named, t.name = true, value
+		case "default":
+			t.defaulted, t.value = true, value
+		default:
+			return t, fmt.Errorf("unknown option %q", opt)
+		}
+	}
+	return
+}
+
+// setDefault sets the field v of a string, bool or numeric type to value.
+func setDefault(v reflect.Value, value string) (err error) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(value); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			var d time.Duration
+			d, err = time.ParseDuration(value)
+			n = int64(d)
+		} else {
+			n, err = strconv.ParseInt(value, 0, v.Type().Bits())
+		}
+		if err == nil {
+			v.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		if n, err = strconv.ParseUint(value, 0, v.Type().Bits()); err == nil {
+			v.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(value, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	default:
+		err = errors.New("defaults apply to string, bool and numeric fields only")
+	}
+	return
+}
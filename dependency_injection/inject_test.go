@@ -0,0 +1,127 @@
+package dependency_injection
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type injectTarget struct {
+	Config   *testConfig
+	Greeter  testGreeter
+	Store    testStore   `di:"optional"`
+	Skipped  *testConfig `di:"-"`
+	internal *testConfig
+}
+
+func TestInjectFields(t *testing.T) {
+	di := NewDependencyInjection()
+	config := &testConfig{name: "app"}
+	english := &testEnglish{}
+	di.Add(config)
+	di.Add(english)
+
+	var target injectTarget
+	if err := InjectAll(di, &target); err != nil {
+		t.Fatal(err)
+	}
+	if target.Config != config {
+		t.Fatal("pointer field not injected")
+	}
+	if target.Greeter != english {
+		t.Fatal("interface field not injected with the implementing value")
+	}
+	if target.Store != nil {
+		t.Fatal("optional field set although nothing resolves")
+	}
+	if target.Skipped != nil || target.internal != nil {
+		t.Fatal("skipped or unexported field injected")
+	}
+}
+
+func TestInjectMissingField(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&testConfig{})
+
+	var target injectTarget
+	err := InjectAll(di, &target)
+	if !errors.Is(err, ErrDependencyNotFound) || !strings.Contains(err.Error(), "Greeter") {
+		t.Fatalf("InjectAll with a missing field = %v, want ErrDependencyNotFound naming Greeter", err)
+	}
+	if target.Config == nil {
+		t.Fatal("resolvable field left unset because another field is missing")
+	}
+}
+
+func TestInjectInvalidTarget(t *testing.T) {
+	di := NewDependencyInjection()
+	var nilTarget *injectTarget
+	for name, target := range map[string]interface{}{
+		"struct value":   injectTarget{},
+		"nil pointer":    nilTarget,
+		"pointer to int": new(int),
+		"nil":            nil,
+	} {
+		if err := Inject(di, target); !errors.Is(err, ErrInvalidTarget) {
+			t.Errorf("Inject(%s) = %v, want ErrInvalidTarget", name, err)
+		}
+	}
+}
+
+type taggedTarget struct {
+	Primary  *testConfig `di:"name=primary"`
+	Replica  *testConfig `di:"name=replica,optional"`
+	Config   *testConfig `di:""`
+	Untagged *testConfig
+	Host     string        `di:"default=localhost"`
+	Port     int           `di:"default=8080"`
+	Timeout  time.Duration `di:"default=1.5s"`
+	Labels   string        `di:"default=a,b"`
+}
+
+func TestInjectTags(t *testing.T) {
+	di := NewDependencyInjection()
+	config, primary := &testConfig{name: "typed"}, &testConfig{name: "primary"}
+	di.Add(config)
+	di.AddNamed("primary", primary)
+
+	var target taggedTarget
+	if err := Inject(di, &target); err != nil {
+		t.Fatal(err)
+	}
+	if target.Primary != primary || target.Replica != nil || target.Config != config {
+		t.Fatalf("named fields = %v, %v, %v; want the named, none and the typed config", target.Primary, target.Replica, target.Config)
+	}
+	if target.Untagged != nil {
+		t.Fatal("Inject set a field without a di tag")
+	}
+	if target.Host != "localhost" || target.Port != 8080 || target.Timeout != 1500*time.Millisecond || target.Labels != "a,b" {
+		t.Fatalf("defaults = %q, %d, %v, %q", target.Host, target.Port, target.Timeout, target.Labels)
+	}
+
+	var all taggedTarget
+	if err := InjectAll(di, &all); err != nil || all.Untagged != config {
+		t.Fatalf("InjectAll left the untagged field %v, %v; want the typed config", all.Untagged, err)
+	}
+}
+
+func TestInjectTagErrors(t *testing.T) {
+	di := NewDependencyInjection()
+	di.AddNamed("primary", &testEnglish{})
+
+	var target struct {
+		Primary *testConfig `di:"name=primary"`
+		Port    int         `di:"default=http"`
+		Where   *testConfig `di:"place=here"`
+	}
+	err := Inject(di, &target)
+	if !errors.Is(err, ErrNamedType) || !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("Inject with bad tags = %v, want ErrNamedType and ErrInvalidTag", err)
+	}
+	for _, field := range []string{"Primary", "Port", "Where"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("Inject error %q does not name the field %s", err, field)
+		}
+	}
+}
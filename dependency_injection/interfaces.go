@@ -0,0 +1,148 @@
+package dependency_injection
+
+import (
+	"reflect"
+	"strings"
+)
+
+// implementersOf returns the dependencies of the container implementing the interface t,
+// in registration order, using the interface index. The index of t is built from the
+// global bucket the first time t is requested and kept up to date by later registrations.
+// It must be called with the read lock held, which it may release and reacquire.
+func (di *DependencyInjection) implementersOf(t reflect.Type) []interface{} {
+	if deps, ok := di.info.implementers[t]; ok {
+		return deps
+	}
+	di.info.mutex.RUnlock()
+	di.info.mutex.Lock()
+	if _, ok := di.info.implementers[t]; !ok && !di.info.noInterfaceScan {
+		di.info.index(t)
+		di.info.publish()
+	}
+	di.info.mutex.Unlock()
+	di.info.mutex.RLock()
+
+	if deps, ok := di.info.implementers[t]; ok {
+		return deps
+	}
+	return di.info.order[globalKey]
+}
+
+// index adds the interface t to the interface index, with every registered dependency
+// implementing it.
+func (info *dependencyInjection) index(t reflect.Type) {
+	var deps []interface{}
+	for _, dep := range info.order[globalKey] {
+		if typeKey(dep).Implements(t) {
+			deps = append(deps, dep)
+		}
+	}
+	if info.implementers == nil {
+		info.implementers = make(map[reflect.Type][]interface{})
+	}
+	info.implementers[t] = deps
+}
+
+// indexDep appends dep to the index of every indexed interface it implements.
+func (info *dependencyInjection) indexDep(dep interface{}) {
+	var t = typeKey(dep)
+	for i, deps := range info.implementers {
+		if t.Implements(i) {
+			info.implementers[i] = append(deps, dep)
+		}
+	}
+}
+
+// unindexDep removes the dependency known by identity id from the interface index.
+func (info *dependencyInjection) unindexDep(id interface{}) {
+	for i, deps := range info.implementers {
+		for j := range deps {
+			if identity(deps[j]) == id {
+				info.implementers[i] = append(deps[:j:j], deps[j+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// notFound returns the error of a failed resolution of type key t: an ErrMultipleDependencies
+// error if it stopped at a container holding several, see SetMultiPolicy, a TypeMismatchError
+// if t is an interface and a dependency registered within the container or its parents
+// resembles it, the most recently added one first, or a DependencyNotFoundError otherwise.
+func (di *DependencyInjection) notFound(t reflect.Type) error {
+	if err := di.multiple(t); err != nil {
+		return err
+	}
+	if t.Kind() == reflect.Interface && t.NumMethod() > 0 {
+		var visited visitedSet
+		for c := di; c != nil && visited.add(c.info); c = c.Parent() {
+			c.info.mutex.RLock()
+			var deps = c.info.registered()
+			for i := len(deps) - 1; i >= 0; i-- {
+				if reason, ok := resembles(typeKey(deps[i]), t); ok {
+					c.info.mutex.RUnlock()
+					return &TypeMismatchError{Type: t.String(), Found: typeKey(deps[i]).String(), Reason: reason}
+				}
+			}
+			c.info.mutex.RUnlock()
+		}
+	}
+	return &DependencyNotFoundError{Type: t.String()}
+}
+
+// resembles reports whether type t has a method of the interface i by name without
+// implementing i, and returns the methods of i it misses or has with another signature.
+func resembles(t, i reflect.Type) (string, bool) {
+	if t == nil || t.Implements(i) {
+		return "", false
+	}
+	var shared bool
+	var missing, mismatched []string
+	for j := 0; j < i.NumMethod(); j++ {
+		var want = i.Method(j)
+		var have, ok = t.MethodByName(want.Name)
+		switch {
+		case !ok:
+			missing = append(missing, want.Name)
+		case !sameSignature(have.Type, want.Type, t.Kind() != reflect.Interface):
+			shared = true
+			mismatched = append(mismatched, want.Name)
+		default:
+			shared = true
+		}
+	}
+	if !shared {
+		return "", false
+	}
+	var reasons []string
+	if len(missing) > 0 {
+		reasons = append(reasons, "missing method "+strings.Join(missing, ", "))
+	}
+	if len(mismatched) > 0 {
+		reasons = append(reasons, "wrong signature of "+strings.Join(mismatched, ", "))
+	}
+	return strings.Join(reasons, "; "), true
+}
+
+// sameSignature reports whether the method type have, whose first parameter is the
+// receiver if receiver is set, has the signature of the interface method type want.
+func sameSignature(have, want reflect.Type, receiver bool) bool {
+	var skip int
+	if receiver {
+		skip = 1
+	}
+	if have.NumIn()-skip != want.NumIn() || have.NumOut() != want.NumOut() || have.IsVariadic() != want.IsVariadic() {
+		return false
+	}
+	for k := 0; k < want.NumIn(); k++ {
+		if have.In(k+skip) != want.In(k) {
+			return false
+		}
+	}
+	for k := 0; k < want.NumOut(); k++ {
+		if have.Out(k) != want.Out(k) {
+			return false
+		}
+	}
+	return true
+}
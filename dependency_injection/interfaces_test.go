@@ -0,0 +1,141 @@
+package dependency_injection
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+type testNamer interface {
+	Name() string
+}
+
+type testPerson struct {
+	name string
+}
+
+func (p *testPerson) Greet() string { return "hi " + p.name }
+func (p *testPerson) Name() string  { return p.name }
+
+func TestResolveThroughEachInterface(t *testing.T) {
+	di := NewDependencyInjection()
+	person := &testPerson{name: "ada"}
+	di.Add(person)
+
+	if got := MustAny[testGreeter](di); got != person {
+		t.Fatal("concrete registration did not resolve through testGreeter")
+	}
+	if got := MustAny[testNamer](di); got != person {
+		t.Fatal("concrete registration did not resolve through testNamer")
+	}
+	if got := MustAny[*testPerson](di); got != person {
+		t.Fatal("concrete registration did not resolve as itself")
+	}
+}
+
+func TestInterfaceIndexFollowsChanges(t *testing.T) {
+	di := NewDependencyInjection()
+	first := &testPerson{name: "first"}
+	di.Add(first)
+	MustAny[testNamer](di)
+
+	second := &testPerson{name: "second"}
+	di.Add(second)
+	if got := MustAny[testNamer](di); got != second {
+		t.Fatal("interface index missed a value added after the first resolution")
+	}
+	di.Remove(second)
+	if got := MustAny[testNamer](di); got != first {
+		t.Fatal("interface index kept a removed value")
+	}
+	if n := len(All[testNamer](di)); n != 1 {
+		t.Fatalf("All[testNamer] = %d, want 1", n)
+	}
+}
+
+func TestResolveStandardInterfaces(t *testing.T) {
+	di := NewDependencyInjection()
+	buf := &bytes.Buffer{}
+	AddAs[io.Writer](di, buf)
+
+	if got := MustAny[io.Reader](di); got != buf {
+		t.Fatal("*bytes.Buffer added as io.Writer did not resolve as io.Reader")
+	}
+	if got := MustAny[io.Writer](di); got != buf {
+		t.Fatal("*bytes.Buffer did not resolve as io.Writer")
+	}
+	if got := MustAny[io.ReadWriter](di); got != buf {
+		t.Fatal("*bytes.Buffer did not resolve as io.ReadWriter")
+	}
+	if got := MustAny[fmt.Stringer](di); got != buf {
+		t.Fatal("*bytes.Buffer did not resolve as fmt.Stringer")
+	}
+	if _, ok := TryAny[io.Closer](di); ok {
+		t.Fatal("*bytes.Buffer resolved as io.Closer, which it does not implement")
+	}
+}
+
+type testRenamer interface {
+	Name() string
+	Rename(name string) error
+}
+
+type testCounter interface {
+	Name() string
+	Count() int
+}
+
+type testPartial struct{}
+
+func (testPartial) Name() string { return "partial" }
+func (testPartial) Count() int64 { return 0 }
+
+func TestAnyReportsTypeMismatch(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&testPerson{name: "ann"})
+
+	var renamer testRenamer
+	err := Any(di, &renamer)
+	var mismatch *TypeMismatchError
+	if !errors.As(err, &mismatch) || !errors.Is(err, ErrTypeMismatch) || !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("Any[testRenamer] = %v, want a TypeMismatchError", err)
+	}
+	if mismatch.Found != "*dependency_injection.testPerson" || !strings.Contains(mismatch.Reason, "missing method Rename") {
+		t.Fatalf("TypeMismatchError = %+v, want *testPerson missing Rename", mismatch)
+	}
+
+	di.Add(testPartial{})
+	var counter testCounter
+	if err := Any(di, &counter); !errors.As(err, &mismatch) || !strings.Contains(mismatch.Reason, "wrong signature of Count") {
+		t.Fatalf("Any[testCounter] = %v, want a wrong signature of Count", err)
+	}
+
+	var closer io.Closer
+	if err := Any(di, &closer); errors.Is(err, ErrTypeMismatch) || !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("Any[io.Closer] = %v, want a plain ErrDependencyNotFound", err)
+	}
+}
+
+func TestAddEachRegistersElements(t *testing.T) {
+	di := NewDependencyInjection()
+	handlers := []testNamer{&testPerson{name: "a"}, &testPerson{name: "b"}, &testPerson{name: "c"}}
+	di.AddEach(handlers)
+
+	if got := All[testNamer](di); len(got) != 3 || got[0] != handlers[0] || got[2] != handlers[2] {
+		t.Fatalf("All after AddEach of three = %v, want the three elements in order", got)
+	}
+	if got := MustAny[testNamer](di); got != handlers[2] {
+		t.Fatalf("Any after AddEach = %v, want the last element", got)
+	}
+	if _, ok := TryAny[[]testNamer](di); ok {
+		t.Fatal("AddEach registered the slice as a whole")
+	}
+
+	di.AddEach(nil)
+	if err, _ := panicOf(t, func() { di.AddEach(&testPerson{}) }).(error); err == nil {
+		t.Fatal("AddEach of a non-slice did not panic")
+	}
+}
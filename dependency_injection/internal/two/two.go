@@ -0,0 +1,13 @@
+// Package two declares types sharing their names with those of its sibling packages,
+// so tests can check that dependencies are keyed by type rather than by name.
+package two
+
+// Config has the same name in every sibling package.
+type Config struct {
+	Name string
+}
+
+// Box is a generic type with the same name in every sibling package.
+type Box[T any] struct {
+	Value T
+}
@@ -0,0 +1,38 @@
+package dependency_injection
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AddJSON registers each top-level value of the JSON object data within the container
+// under its key, as with AddNamed, so that it resolves with Named(...). Values decode as
+// with encoding/json into interface{}: strings as string, numbers as float64, booleans as
+// bool, arrays as []interface{} and nested objects as map[string]interface{}. Nothing is
+// registered if data is not a JSON object.
+func (di *DependencyInjection) AddJSON(data []byte) error {
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("AddJSON: %w", err)
+	}
+	if values == nil {
+		return fmt.Errorf("AddJSON: not a JSON object")
+	}
+
+	di.info.mutex.Lock()
+
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		return nil
+	}
+
+	if di.info.named == nil {
+		di.info.named = make(map[string]interface{}, len(values))
+	}
+	for name, value := range values {
+		di.info.named[name] = value
+	}
+
+	di.info.mutex.Unlock()
+	return nil
+}
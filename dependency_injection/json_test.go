@@ -0,0 +1,55 @@
+package dependency_injection
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddJSONRegistersNamedValues(t *testing.T) {
+	di := NewDependencyInjection()
+	err := di.AddJSON([]byte(`{
+		"apiKey": "secret",
+		"port": 8080,
+		"debug": true,
+		"hosts": ["a", "b"],
+		"db": {"user": "app", "pool": 4}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := MustNamed[string](di, "apiKey"); got != "secret" {
+		t.Fatalf("apiKey = %q, want secret", got)
+	}
+	if got := MustNamed[float64](di, "port"); got != 8080 {
+		t.Fatalf("port = %v, want 8080", got)
+	}
+	if got := MustNamed[bool](di, "debug"); !got {
+		t.Fatal("debug = false, want true")
+	}
+	if got := MustNamed[[]interface{}](di, "hosts"); len(got) != 2 || got[1] != "b" {
+		t.Fatalf("hosts = %v, want [a b]", got)
+	}
+	if got := MustNamed[map[string]interface{}](di, "db"); got["user"] != "app" || got["pool"] != 4.0 {
+		t.Fatalf("db = %v, want the nested object", got)
+	}
+	if _, err := Named[int](di, "port"); !errors.Is(err, ErrNamedType) {
+		t.Fatalf("Named[int] of a JSON number = %v, want ErrNamedType", err)
+	}
+}
+
+func TestAddJSONRejectsNonObject(t *testing.T) {
+	di := NewDependencyInjection()
+	for _, data := range []string{`[1, 2]`, `null`, `{"broken"`} {
+		if err := di.AddJSON([]byte(data)); err == nil {
+			t.Fatalf("AddJSON(%s) = nil, want an error", data)
+		}
+	}
+	di.AddNamed("kept", "value")
+	if err := di.AddJSON([]byte(`"text"`)); err == nil {
+		t.Fatal("AddJSON of a string = nil, want an error")
+	}
+	if got := MustNamed[string](di, "kept"); got != "value" {
+		t.Fatalf("failed AddJSON changed the names: %q", got)
+	}
+}
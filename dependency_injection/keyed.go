@@ -0,0 +1,85 @@
+package dependency_injection
+
+import (
+	"fmt"
+)
+
+// AddWithKey registers a dependency within the container under a custom key, for
+// indexing schemes built on top of the container, e.g. an interface name with a
+// discriminator. Custom keys live in their own keyspace, separate from both the type
+// keys derived from dependencies and the names of AddNamed, and registering an existing
+// key replaces the previous dependency. The dependency is resolved by AnyByKey(...) only.
+// It is AddKeyed(...) with a string key.
+func (di *DependencyInjection) AddWithKey(key string, dep interface{}) {
+	di.addKeyed(key, dep)
+}
+
+// RemoveKey unregisters the dependency registered under the given custom key.
+func (di *DependencyInjection) RemoveKey(key string) {
+	RemoveKeyed(di, key)
+}
+
+// AnyByKey retrieves the dependency of type T registered under the given custom key with
+// AddWithKey(...), falling back to the parent container if the key is not registered. A key
+// registered with a dependency of another type returns ErrNamedType instead of looking further.
+func AnyByKey[T any](di *DependencyInjection, key string) (T, error) {
+	return Keyed[string, T](di, key)
+}
+
+// AddKeyed registers a dependency within the container under a custom key of a type of
+// the caller's own, such as type Region int, like AddWithKey(...) does with strings. Keys
+// of distinct types never collide, even if their values are equal.
+func AddKeyed[K comparable, T any](di *DependencyInjection, key K, dep T) {
+	di.addKeyed(key, dep)
+}
+
+// RemoveKeyed unregisters the dependency registered under the given custom key.
+func RemoveKeyed[K comparable](di *DependencyInjection, key K) {
+	di.info.mutex.Lock()
+
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		return
+	}
+
+	delete(di.info.keyed, key)
+
+	di.info.mutex.Unlock()
+}
+
+// Keyed retrieves the dependency of type T registered under the given custom key with
+// AddKeyed(...), falling back to the parent container if the key is not registered. A key
+// registered with a dependency of another type returns ErrNamedType instead of looking further.
+func Keyed[K comparable, T any](di *DependencyInjection, key K) (result T, err error) {
+	var visited visitedSet
+	for ; di != nil && visited.add(di.info); di = di.Parent() {
+		di.info.mutex.RLock()
+		dep, found := di.info.keyed[key]
+		di.info.mutex.RUnlock()
+
+		if found {
+			if result, ok := dep.(T); ok {
+				return result, nil
+			}
+			return result, fmt.Errorf("%w: key %#v is %v, not %s", ErrNamedType, key, typeKey(dep), keyFor[T]())
+		}
+	}
+	return result, fmt.Errorf("%w: key %#v of type %s", ErrDependencyNotFound, key, keyFor[T]())
+}
+
+// addKeyed registers a dependency within the container under the custom key.
+func (di *DependencyInjection) addKeyed(key interface{}, dep interface{}) {
+	di.info.mutex.Lock()
+
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		return
+	}
+
+	if di.info.keyed == nil {
+		di.info.keyed = make(map[interface{}]interface{})
+	}
+	di.info.keyed[key] = dep
+
+	di.info.mutex.Unlock()
+}
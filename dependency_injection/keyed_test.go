@@ -0,0 +1,100 @@
+package dependency_injection
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddWithKeyRoundTrip(t *testing.T) {
+	parent := NewDependencyInjection()
+	primary := &testMemoryStore{}
+	parent.AddWithKey("testStore#primary", primary)
+	di := NewChild(parent)
+	replica := &testMemoryStore{}
+	di.AddWithKey("testStore#replica", replica)
+
+	if got, err := AnyByKey[testStore](di, "testStore#replica"); err != nil || got != replica {
+		t.Fatalf("AnyByKey replica = %v, %v", got, err)
+	}
+	if got, err := AnyByKey[*testMemoryStore](di, "testStore#primary"); err != nil || got != primary {
+		t.Fatalf("AnyByKey primary through the parent = %v, %v", got, err)
+	}
+	if _, err := AnyByKey[*testConfig](di, "testStore#replica"); !errors.Is(err, ErrNamedType) {
+		t.Fatalf("AnyByKey of another type = %v, want ErrNamedType", err)
+	}
+
+	di.RemoveKey("testStore#replica")
+	if _, err := AnyByKey[testStore](di, "testStore#replica"); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("AnyByKey after RemoveKey = %v, want ErrDependencyNotFound", err)
+	}
+}
+
+func TestAddWithKeySeparateKeyspace(t *testing.T) {
+	di := NewDependencyInjection()
+	keyed := &testConfig{name: "keyed"}
+	di.AddWithKey("config", keyed)
+
+	if _, ok := TryAny[*testConfig](di); ok {
+		t.Fatal("dependency under a custom key resolved by type")
+	}
+	if _, err := Named[*testConfig](di, "config"); err == nil {
+		t.Fatal("dependency under a custom key resolved by name")
+	}
+	di.AddNamed("config", &testConfig{name: "named"})
+	if got, _ := AnyByKey[*testConfig](di, "config"); got != keyed {
+		t.Fatalf("AddNamed replaced the custom key: %v", got)
+	}
+}
+
+type testRegion int
+
+const (
+	regionEU testRegion = iota + 1
+	regionUS
+)
+
+type testShard struct {
+	region testRegion
+	n      int
+}
+
+func TestKeyedIntKey(t *testing.T) {
+	di := NewDependencyInjection()
+	eu, us := &testMemoryStore{}, &testMemoryStore{}
+	AddKeyed[testRegion, testStore](di, regionEU, eu)
+	AddKeyed[testRegion, testStore](di, regionUS, us)
+	AddKeyed(di, 1, &testConfig{name: "int"})
+
+	if got, err := Keyed[testRegion, testStore](di, regionEU); err != nil || got != eu {
+		t.Fatalf("Keyed(regionEU) = %v, %v", got, err)
+	}
+	if got, err := Keyed[testRegion, testStore](di, regionUS); err != nil || got != us {
+		t.Fatalf("Keyed(regionUS) = %v, %v", got, err)
+	}
+	if got, err := Keyed[int, *testConfig](di, 1); err != nil || got.name != "int" {
+		t.Fatalf("Keyed(1) = %v, %v; want the int key apart from regionEU", got, err)
+	}
+
+	RemoveKeyed(di, regionUS)
+	if _, err := Keyed[testRegion, testStore](di, regionUS); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("Keyed after RemoveKeyed = %v, want ErrDependencyNotFound", err)
+	}
+}
+
+func TestKeyedStructKey(t *testing.T) {
+	parent := NewDependencyInjection()
+	first := &testConfig{name: "eu-0"}
+	AddKeyed(parent, testShard{region: regionEU}, first)
+	di := NewChild(parent)
+	AddKeyed(di, testShard{region: regionEU, n: 1}, &testConfig{name: "eu-1"})
+
+	if got, err := Keyed[testShard, *testConfig](di, testShard{region: regionEU}); err != nil || got != first {
+		t.Fatalf("Keyed of a struct key through the parent = %v, %v", got, err)
+	}
+	if got, err := Keyed[testShard, *testConfig](di, testShard{region: regionEU, n: 1}); err != nil || got.name != "eu-1" {
+		t.Fatalf("Keyed of a struct key = %v, %v", got, err)
+	}
+	if _, err := Keyed[testShard, testStore](di, testShard{region: regionEU, n: 1}); !errors.Is(err, ErrNamedType) {
+		t.Fatalf("Keyed of another type = %v, want ErrNamedType", err)
+	}
+}
@@ -0,0 +1,60 @@
+package dependency_injection
+
+import (
+	"context"
+	"errors"
+)
+
+// Lifecycle is implemented by services that run in the background between Start and
+// Stop of the container they are registered within.
+type Lifecycle interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Start calls Start of every dependency registered within the container that implements
+// Lifecycle, in registration order. Once one fails, no further services are started and
+// those already started are stopped again, in reverse order, and the returned error joins
+// the error of the failing Start with those of the rollback. Parent containers are not started.
+func (di *DependencyInjection) Start(ctx context.Context) error {
+	var services = di.lifecycles()
+	for i, service := range services {
+		if err := service.Start(ctx); err != nil {
+			var errs = []error{err}
+			for j := i - 1; j >= 0; j-- {
+				if err := services[j].Stop(ctx); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return errors.Join(errs...)
+		}
+	}
+	return nil
+}
+
+// Stop calls Stop of every dependency registered within the container that implements
+// Lifecycle, in reverse registration order. A failing Stop does not prevent stopping the
+// others; the errors of all failing services are returned joined.
+func (di *DependencyInjection) Stop(ctx context.Context) error {
+	var services = di.lifecycles()
+	var errs []error
+	for i := len(services) - 1; i >= 0; i-- {
+		if err := services[i].Stop(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// lifecycles returns the dependencies registered within the container that implement
+// Lifecycle, in registration order.
+func (di *DependencyInjection) lifecycles() (services []Lifecycle) {
+	di.info.mutex.RLock()
+	for _, dep := range di.info.registered() {
+		if service, ok := dep.(Lifecycle); ok {
+			services = append(services, service)
+		}
+	}
+	di.info.mutex.RUnlock()
+	return
+}
@@ -0,0 +1,73 @@
+package dependency_injection
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type testService struct {
+	name   string
+	events *[]string
+	fail   error
+}
+
+func (s *testService) Start(ctx context.Context) error {
+	if s.fail != nil {
+		return s.fail
+	}
+	*s.events = append(*s.events, "start "+s.name)
+	return nil
+}
+
+func (s *testService) Stop(ctx context.Context) error {
+	*s.events = append(*s.events, "stop "+s.name)
+	return nil
+}
+
+func TestStartStopOrder(t *testing.T) {
+	di := NewDependencyInjection()
+	var events []string
+	di.Add(&testService{name: "db", events: &events})
+	di.Add(&testConfig{})
+	di.Add(&testService{name: "http", events: &events})
+
+	if err := di.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := di.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"start db", "start http", "stop http", "stop db"}
+	if len(events) != len(want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("events = %v, want %v", events, want)
+		}
+	}
+}
+
+func TestStartRollsBackOnFailure(t *testing.T) {
+	di := NewDependencyInjection()
+	var events []string
+	failed := errors.New("port in use")
+	di.Add(&testService{name: "db", events: &events})
+	di.Add(&testService{name: "cache", events: &events})
+	di.Add(&testService{name: "http", events: &events, fail: failed})
+	di.Add(&testService{name: "worker", events: &events})
+
+	if err := di.Start(context.Background()); !errors.Is(err, failed) {
+		t.Fatalf("Start = %v, want the failing Start", err)
+	}
+	want := []string{"start db", "start cache", "stop cache", "stop db"}
+	if len(events) != len(want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("events = %v, want %v", events, want)
+		}
+	}
+}
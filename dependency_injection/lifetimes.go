@@ -1,13 +1,89 @@
 package dependency_injection
 
-import "runtime"
+import (
+	"math"
+	"reflect"
+	"runtime"
+)
+
+// Lifetime describes how a DependencyInjection shares the objects made from it.
+type Lifetime int
+
+const (
+	// Singleton containers create one object per type, shared globally.
+	Singleton Lifetime = iota
+	// Scoped containers create one object per type, shared within the scope.
+	Scoped
+	// Transient containers create a new object for each request.
+	Transient
+	// Pooled containers hand out objects from a small pool.
+	Pooled
+)
+
+// Lifetime returns the lifetime of the container.
+func (di *DependencyInjection) Lifetime() Lifetime {
+	if di.IsTransient() {
+		return Transient
+	}
+	return di.lifetime
+}
+
+// MustNew injects a dependency of type T using the given constructor function, respecting
+// the lifetime of the container: Transient containers make a new object on each call,
+// others make it once and cache it like MustNeed does.
+func MustNew[T any](di *DependencyInjection, newer func(di *DependencyInjection) *T) T {
+	if di.Lifetime() == Transient {
+		return Make(di, newer)
+	}
+	return MustNeed(di, newer)
+}
+
+// Make makes a new dependency of type T using the given constructor function on every
+// call and never caches it, regardless of the lifetime of the container, unlike MustNeed.
+// As with MustNeed, a constructor requesting T again panics with the cycle.
+func Make[T any](di *DependencyInjection, newer func(di *DependencyInjection) *T) T {
+	return construct(di, newer)
+}
+
+// SingletonOf returns the shared *T of the container: an existing one resolved like
+// MustNeed(...) does, or else one made by newer, cached as a *T and returned by every
+// later call, so concurrent callers all get the identical pointer from a single call of
+// newer. Transient containers make a new one on each call, as with MustNeed.
+func SingletonOf[T any](di *DependencyInjection, newer func(di *DependencyInjection) *T) *T {
+	return MustNeed(di, func(di *DependencyInjection) **T {
+		ptr := newer(di)
+		return &ptr
+	})
+}
+
+// NewSingletonDependencyInjection creates a DependencyInjection for injection using
+// the Singleton lifetime. Each MustNew(...) object made from the result is created once
+// per type and cached in the parent, so it is shared with resolutions from the parent,
+// unlike Scoped which keeps its objects to itself.
+func NewSingletonDependencyInjection(di *DependencyInjection) *DependencyInjection {
+	child := NewChild(di)
+	child.info.shared = true
+	return child
+}
+
+// owner returns the container in which MustNeed(...) caches the objects it makes:
+// the container itself, or the nearest ancestor of a Singleton container. The handle
+// keeps the resolution in progress of di.
+func (di *DependencyInjection) owner() *DependencyInjection {
+	var owner = di
+	for owner.info.shared && owner.Parent() != nil {
+		owner = owner.Parent()
+	}
+	if owner == di {
+		return di
+	}
+	return owner.along(di.path)
+}
 
 // NewTransientDependencyInjection creates a DependencyInjection for injection using
 // the Transient lifetime. Each MustNew(...) object made from the result is newly allocated.
-func NewTransientDependencyInjection(di *DependencyInjection) (*DependencyInjection) {
-	child := NewDependencyInjection()
-	// must be before SetTransient
-	child.Add(di)
+func NewTransientDependencyInjection(di *DependencyInjection) *DependencyInjection {
+	child := NewChild(di)
 	// freeze it
 	child.SetTransient(true)
 	return child
@@ -16,26 +92,41 @@ func NewTransientDependencyInjection(di *DependencyInjection) (*DependencyInject
 // NewScopedDependencyInjection creates a DependencyInjection for injection using
 // the Scoped lifetime. Each MustNew(...) object made from the result is scoped,
 // multiple instances for equal type objects are not newly allocated (one singleton per type).
-func NewScopedDependencyInjection(di *DependencyInjection) (*DependencyInjection) {
-	child := NewDependencyInjection()
-	child.Add(di)
+func NewScopedDependencyInjection(di *DependencyInjection) *DependencyInjection {
+	child := NewChild(di)
+	child.lifetime = Scoped
 	return child
 }
 
 // NewPooledDependencyInjection creates a DependencyInjection for injection using
-// the Pooled lifetime. Each MustNew(...) object made from the result is from a pool
-// of small number of objects, dynamically adjusting to load.
-func NewPooledDependencyInjection(di *DependencyInjection) (*DependencyInjection) {
-	return Ptr(MustNeed(di, func (parent *DependencyInjection) (*DependencyInjection) {
-		child := NewDependencyInjection()
-		clone := Ptr(*parent)
-		clone.Add(child)
-		runtime.SetFinalizer(clone, func(s *DependencyInjection) {
-			clone.Remove(*child)
-			clone = nil
-			parent = nil
-			child = nil
-		})
-		return clone
-	}))
+// the Pooled lifetime. Objects are checked out of a pool per type with Acquire(...) and
+// handed back with Release(...). Acquire never blocks, making objects as load demands,
+// and up to one idle object per CPU is kept for reuse; Release closes the others.
+// Dispose() closes the idle objects, so nothing depends on the garbage collector.
+func NewPooledDependencyInjection(di *DependencyInjection) *DependencyInjection {
+	return newPooled(di, 0, math.MaxInt, runtime.GOMAXPROCS(0))
+}
+
+// NewPooledDependencyInjectionSized creates a DependencyInjection for injection using
+// the Pooled lifetime with a bounded pool per type. Objects are checked out with
+// Acquire(...) and returned with Release(...). The first Acquire of a type makes min
+// objects, more are made lazily up to max, after which Acquire blocks until
+// an object is released.
+func NewPooledDependencyInjectionSized(di *DependencyInjection, min, max int) *DependencyInjection {
+	if max < 1 {
+		max = 1
+	}
+	if min > max {
+		min = max
+	}
+	return newPooled(di, min, max, max)
+}
+
+// newPooled creates a pooled child container whose pool of each type keeps up to idle
+// objects, out of at most max made, min of them on the first Acquire(...).
+func newPooled(di *DependencyInjection, min, max, idle int) *DependencyInjection {
+	child := NewChild(di)
+	child.lifetime = Pooled
+	child.info.pool = &pool{min: min, max: max, idle: idle, objects: make(map[reflect.Type]*objectPool)}
+	return child
 }
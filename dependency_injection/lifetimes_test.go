@@ -0,0 +1,329 @@
+package dependency_injection
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type lifetimeCounter struct {
+	n int
+}
+
+func TestSingletonIsChildOfParent(t *testing.T) {
+	parent := NewDependencyInjection()
+	singleton := NewSingletonDependencyInjection(parent)
+
+	if singleton.Parent() != parent {
+		t.Fatal("Parent() of a Singleton container is not the container it was made from")
+	}
+	if singleton.Lifetime() != Singleton {
+		t.Fatalf("Lifetime() = %v, want Singleton", singleton.Lifetime())
+	}
+
+	singleton.SetTransient(true)
+	if parent.IsTransient() {
+		t.Fatal("SetTransient on a Singleton container made the parent transient")
+	}
+}
+
+func TestSingletonSharesWithParent(t *testing.T) {
+	parent := NewDependencyInjection()
+	singleton := NewSingletonDependencyInjection(parent)
+
+	var calls int
+	newer := func(*DependencyInjection) *lifetimeCounter {
+		calls++
+		return &lifetimeCounter{n: calls}
+	}
+
+	first := MustNew(singleton, newer)
+	second := MustNew(singleton, newer)
+	if first.n != 1 || second.n != 1 || calls != 1 {
+		t.Fatalf("MustNew made %d objects, want 1", calls)
+	}
+	if got, ok := TryAny[lifetimeCounter](parent); !ok || got.n != 1 {
+		t.Fatalf("parent TryAny = %v, %v; want the Singleton object", got, ok)
+	}
+	if got := MustNeed(parent, newer); got.n != 1 || calls != 1 {
+		t.Fatalf("parent MustNeed made another object: %v", got)
+	}
+}
+
+func TestSingletonDisposeKeepsParent(t *testing.T) {
+	parent := NewDependencyInjection()
+	parent.Add(lifetimeCounter{n: 7})
+	singleton := NewSingletonDependencyInjection(parent)
+
+	if err := singleton.Dispose(); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := TryAny[lifetimeCounter](parent); !ok || got.n != 7 {
+		t.Fatalf("Dispose of a Singleton container disposed the parent: %v, %v", got, ok)
+	}
+}
+
+func TestScopedKeepsObjectsToItself(t *testing.T) {
+	parent := NewDependencyInjection()
+	scope := NewScopedDependencyInjection(parent)
+
+	MustNew(scope, func(*DependencyInjection) *lifetimeCounter {
+		return &lifetimeCounter{n: 1}
+	})
+	if _, ok := TryAny[lifetimeCounter](parent); ok {
+		t.Fatal("object made in a Scoped container is visible from the parent")
+	}
+}
+
+func TestTransientMakesNewObjects(t *testing.T) {
+	transient := NewTransientDependencyInjection(NewDependencyInjection())
+
+	var calls int
+	newer := func(*DependencyInjection) *lifetimeCounter {
+		calls++
+		return &lifetimeCounter{n: calls}
+	}
+	MustNew(transient, newer)
+	MustNew(transient, newer)
+	if calls != 2 {
+		t.Fatalf("Transient MustNew made %d objects, want 2", calls)
+	}
+}
+
+func TestSingletonMustNeedIdenticalPointer(t *testing.T) {
+	singleton := NewSingletonDependencyInjection(NewDependencyInjection())
+	newer := func(*DependencyInjection) **lifetimeCounter {
+		c := &lifetimeCounter{}
+		return &c
+	}
+	if first, second := MustNeed(singleton, newer), MustNeed(singleton, newer); first != second {
+		t.Fatalf("MustNeed returned %p and %p, want the identical pointer", first, second)
+	}
+}
+
+func TestSetTransientRoundTrips(t *testing.T) {
+	di := NewDependencyInjection()
+	if di.IsTransient() || di.Lifetime() != Singleton {
+		t.Fatal("new container is transient")
+	}
+	di.SetTransient(true)
+	if !di.IsTransient() || di.Lifetime() != Transient {
+		t.Fatal("SetTransient(true) did not make the container transient")
+	}
+	di.SetTransient(false)
+	if di.IsTransient() || di.Lifetime() != Singleton {
+		t.Fatal("SetTransient(false) did not restore the lifetime")
+	}
+}
+
+func TestMustNeedRespectsTransient(t *testing.T) {
+	di := NewDependencyInjection()
+	di.SetTransient(true)
+
+	var calls int
+	newer := func(*DependencyInjection) *lifetimeCounter {
+		calls++
+		return &lifetimeCounter{n: calls}
+	}
+	MustNeed(di, newer)
+	MustNeed(di, newer)
+	if calls != 2 {
+		t.Fatalf("MustNeed on a transient container made %d objects, want 2", calls)
+	}
+	if _, ok := TryAny[lifetimeCounter](di); ok {
+		t.Fatal("MustNeed cached an object in a transient container")
+	}
+}
+
+func TestMustNewAllocations(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		make  func(*DependencyInjection) *DependencyInjection
+		calls int
+	}{
+		{"Singleton", NewSingletonDependencyInjection, 1},
+		{"Scoped", NewScopedDependencyInjection, 1},
+		{"Transient", NewTransientDependencyInjection, 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			di := tc.make(NewDependencyInjection())
+
+			var calls int
+			newer := func(*DependencyInjection) *lifetimeCounter {
+				calls++
+				return &lifetimeCounter{n: calls}
+			}
+			for i := 0; i < 3; i++ {
+				MustNew(di, newer)
+			}
+			if calls != tc.calls {
+				t.Fatalf("three MustNew calls made %d objects, want %d", calls, tc.calls)
+			}
+		})
+	}
+}
+
+func TestScopedCachesPerScope(t *testing.T) {
+	parent := NewDependencyInjection()
+	one, two := NewScopedDependencyInjection(parent), NewScopedDependencyInjection(parent)
+	newer := func(*DependencyInjection) **lifetimeCounter {
+		c := &lifetimeCounter{}
+		return &c
+	}
+
+	a, b := MustNeed(one, newer), MustNeed(one, newer)
+	if a != b {
+		t.Fatal("repeated MustNeed within a scope made distinct objects")
+	}
+	if c := MustNeed(two, newer); c == a {
+		t.Fatal("two scopes from the same parent shared an object")
+	}
+}
+
+func TestMustNeedTransientContainerDistinct(t *testing.T) {
+	newer := func(*DependencyInjection) **lifetimeCounter {
+		c := &lifetimeCounter{}
+		return &c
+	}
+	transient := NewTransientDependencyInjection(NewDependencyInjection())
+	if MustNeed(transient, newer) == MustNeed(transient, newer) {
+		t.Fatal("MustNeed on a transient container returned the identical pointer twice")
+	}
+	di := NewDependencyInjection()
+	if MustNeed(di, newer) != MustNeed(di, newer) {
+		t.Fatal("MustNeed on a non-transient container returned distinct pointers")
+	}
+}
+
+func TestMakeNeverCaches(t *testing.T) {
+	di := NewDependencyInjection()
+	newer := func(*DependencyInjection) **lifetimeCounter {
+		c := &lifetimeCounter{}
+		return &c
+	}
+	if Make(di, newer) == Make(di, newer) {
+		t.Fatal("two Make calls on a non-transient container returned the identical pointer")
+	}
+	if _, ok := TryAny[*lifetimeCounter](di); ok {
+		t.Fatal("Make cached its object in the container")
+	}
+}
+
+func TestConstructorsSetLifetime(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		make     func(*DependencyInjection) *DependencyInjection
+		lifetime Lifetime
+	}{
+		{"NewChild", NewChild, Singleton},
+		{"NewSingletonDependencyInjection", NewSingletonDependencyInjection, Singleton},
+		{"NewScopedDependencyInjection", NewScopedDependencyInjection, Scoped},
+		{"NewTransientDependencyInjection", NewTransientDependencyInjection, Transient},
+		{"NewPooledDependencyInjection", NewPooledDependencyInjection, Pooled},
+		{"NewPooledDependencyInjectionSized", func(di *DependencyInjection) *DependencyInjection {
+			return NewPooledDependencyInjectionSized(di, 1, 2)
+		}, Pooled},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.make(NewDependencyInjection()).Lifetime(); got != tc.lifetime {
+				t.Fatalf("Lifetime() = %v, want %v", got, tc.lifetime)
+			}
+		})
+	}
+	if got := NewDependencyInjection().Lifetime(); got != Singleton {
+		t.Fatalf("NewDependencyInjection().Lifetime() = %v, want Singleton", got)
+	}
+}
+
+func TestScopeInheritsParentConfiguration(t *testing.T) {
+	parent := NewDependencyInjection(WithMaxDepth(1), WithInterfaceScan(false))
+	var seen []string
+	parent.OnResolve(func(typeName string, found bool) { seen = append(seen, typeName) })
+	parent.SetStrict(true)
+	scope := NewScopedDependencyInjection(parent)
+
+	var config *testConfig
+	_ = Any(scope, &config)
+	if len(seen) != 1 || seen[0] != "*dependency_injection.testConfig" {
+		t.Fatalf("parent hook saw %v for a resolution through the scope", seen)
+	}
+
+	_, err := Need(scope, func(di *DependencyInjection) (*factoryA, error) {
+		b, err := Need(di, func(*DependencyInjection) (*factoryB, error) { return &factoryB{}, nil })
+		return &factoryA{b: &b}, err
+	})
+	if !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Fatalf("scope Need nested two deep = %v, want the maximum depth of the parent", err)
+	}
+
+	scope.Add(&testMemoryStore{})
+	if _, ok := TryAny[testStore](scope); ok {
+		t.Fatal("scope scanned interfaces although the parent does not")
+	}
+
+	scope.Add(testConfig{})
+	if err, _ := panicOf(t, func() { scope.Add(&testConfig{}) }).(error); !errors.Is(err, ErrAmbiguous) {
+		t.Fatalf("scope Add of an ambiguous pointer panicked with %v, want the strict mode of the parent", err)
+	}
+
+	parent.SetMultiPolicy(Error)
+	policed := NewChild(parent)
+	policed.Add(&testEnglish{accent: "a"})
+	policed.Add(&testEnglish{accent: "b"})
+	if err := Any(policed, new(*testEnglish)); !errors.Is(err, ErrMultipleDependencies) {
+		t.Fatalf("child Any of two = %v, want the MultiPolicy of the parent", err)
+	}
+	if err := Any(policed.Clone(), new(*testEnglish)); !errors.Is(err, ErrMultipleDependencies) {
+		t.Fatalf("clone of the child Any of two = %v, want the MultiPolicy kept", err)
+	}
+
+	scope.SetMaxDepth(0)
+	if _, err := Need(scope, func(di *DependencyInjection) (*factoryA, error) {
+		b, err := Need(di, func(*DependencyInjection) (*factoryB, error) { return &factoryB{}, nil })
+		return &factoryA{b: &b}, err
+	}); err != nil {
+		t.Fatalf("scope kept the parent's maximum depth after overriding it: %v", err)
+	}
+}
+
+func TestSingletonOfMakesOneInstance(t *testing.T) {
+	di := NewDependencyInjection()
+	var calls atomic.Int32
+	newer := func(*DependencyInjection) *lifetimeCounter {
+		calls.Add(1)
+		time.Sleep(time.Millisecond)
+		return &lifetimeCounter{n: 1}
+	}
+
+	var got [8]*lifetimeCounter
+	var wg sync.WaitGroup
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i] = SingletonOf(di, newer)
+		}(i)
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("parallel SingletonOf calls ran the constructor %d times, want once", calls.Load())
+	}
+	for _, c := range got {
+		if c != got[0] {
+			t.Fatalf("parallel SingletonOf calls returned %p and %p, want the identical pointer", got[0], c)
+		}
+	}
+	if c := MustAny[*lifetimeCounter](di); c != got[0] {
+		t.Fatal("SingletonOf did not cache its pointer in the container")
+	}
+
+	existing := &lifetimeCounter{n: 2}
+	other := NewDependencyInjection()
+	other.Add(existing)
+	if c := SingletonOf(other, newer); c != existing || calls.Load() != 1 {
+		t.Fatalf("SingletonOf with a registered *T = %p, want the registered %p", c, existing)
+	}
+}
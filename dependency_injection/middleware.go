@@ -0,0 +1,47 @@
+package dependency_injection
+
+import (
+	"reflect"
+)
+
+// ResolveFunc is a step of resolving the dependency registered under type key t, which
+// reports whether it was found.
+type ResolveFunc func(t reflect.Type) (dep interface{}, found bool)
+
+// Use installs a middleware around every resolution started in the container or the
+// containers below it, e.g. by Any(...), MustNeed(...) or Build(...). The middleware
+// receives the next step and returns the step that replaces it, so it may inspect the
+// requested type, change the outcome, or not call next at all. The last middleware
+// installed runs outermost, and those of a container run outside those of its parents.
+// A dependency returned for a different type than requested is treated as not found.
+// Has(...) does not resolve, so it is not affected.
+func (di *DependencyInjection) Use(mw func(next ResolveFunc) ResolveFunc) {
+	di.info.mutex.Lock()
+	di.info.middlewares = append(di.info.middlewares, mw)
+	di.info.publish()
+	di.info.mutex.Unlock()
+}
+
+// middlewares returns the middlewares of the parent containers and of the container,
+// innermost first.
+func (di *DependencyInjection) middlewares() (middlewares []func(next ResolveFunc) ResolveFunc) {
+	var chain [][]func(next ResolveFunc) ResolveFunc
+	var visited visitedSet
+	for c := di; c != nil && visited.add(c.info); c = c.Parent() {
+		if v := c.info.view.Load(); v != nil {
+			if len(v.middlewares) > 0 {
+				chain = append(chain, v.middlewares)
+			}
+			continue
+		}
+		c.info.mutex.RLock()
+		if len(c.info.middlewares) > 0 {
+			chain = append(chain, c.info.middlewares)
+		}
+		c.info.mutex.RUnlock()
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		middlewares = append(middlewares, chain[i]...)
+	}
+	return
+}
@@ -0,0 +1,119 @@
+package dependency_injection
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type middlewareAdmin struct{}
+
+// blockAdmin is a middleware hiding *middlewareAdmin from every resolution.
+func blockAdmin(next ResolveFunc) ResolveFunc {
+	return func(t reflect.Type) (interface{}, bool) {
+		if t == reflect.TypeOf((*middlewareAdmin)(nil)) {
+			return nil, false
+		}
+		return next(t)
+	}
+}
+
+func TestUseBlocksResolution(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&middlewareAdmin{})
+	di.Use(blockAdmin)
+
+	var admin *middlewareAdmin
+	if err := Any(di, &admin); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("Any through a blocking middleware = %v, want ErrDependencyNotFound", err)
+	}
+	if _, err := AnyContext[*middlewareAdmin](context.Background(), di); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("AnyContext through a blocking middleware = %v, want ErrDependencyNotFound", err)
+	}
+	if !Has[*middlewareAdmin](di) {
+		t.Fatal("Has = false, want the registration reported without running middlewares")
+	}
+}
+
+func TestUseOrder(t *testing.T) {
+	parent := NewDependencyInjection()
+	parent.Add("value")
+	child := NewChild(parent)
+
+	var order []string
+	record := func(name string) func(next ResolveFunc) ResolveFunc {
+		return func(next ResolveFunc) ResolveFunc {
+			return func(t reflect.Type) (interface{}, bool) {
+				order = append(order, name)
+				return next(t)
+			}
+		}
+	}
+	parent.Use(record("parent"))
+	child.Use(record("first"))
+	child.Use(record("second"))
+
+	MustAny[string](child)
+	if want := []string{"second", "first", "parent"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("middlewares ran %v, want %v", order, want)
+	}
+}
+
+func TestUseRunsOnceForAnyContext(t *testing.T) {
+	di := NewDependencyInjection()
+	AddFactory(di, func(*DependencyInjection) *middlewareAdmin { return &middlewareAdmin{} })
+
+	var calls int
+	di.Use(func(next ResolveFunc) ResolveFunc {
+		return func(t reflect.Type) (interface{}, bool) {
+			calls++
+			return next(t)
+		}
+	})
+	if _, err := AnyContext[*middlewareAdmin](context.Background(), di); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatalf("middleware ran %d times for one AnyContext, want 1", calls)
+	}
+}
+
+func TestUseWrongTypeIsNotFound(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add("value")
+	di.Use(func(next ResolveFunc) ResolveFunc {
+		return func(t reflect.Type) (interface{}, bool) {
+			return 42, true
+		}
+	})
+	if _, ok := TryAny[string](di); ok {
+		t.Fatal("a dependency of the wrong type from a middleware was returned")
+	}
+}
+
+func TestUseRecordsAndShortCircuits(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&middlewareAdmin{})
+	di.Add(&testConfig{})
+
+	var requested []reflect.Type
+	di.Use(blockAdmin)
+	di.Use(func(next ResolveFunc) ResolveFunc {
+		return func(t reflect.Type) (interface{}, bool) {
+			requested = append(requested, t)
+			return next(t)
+		}
+	})
+
+	if _, ok := TryAny[*testConfig](di); !ok {
+		t.Fatal("unblocked type did not resolve")
+	}
+	if _, ok := TryAny[*middlewareAdmin](di); ok {
+		t.Fatal("blocked type resolved")
+	}
+	want := []reflect.Type{reflect.TypeOf((*testConfig)(nil)), reflect.TypeOf((*middlewareAdmin)(nil))}
+	if !reflect.DeepEqual(requested, want) {
+		t.Fatalf("recorded %v, want %v", requested, want)
+	}
+}
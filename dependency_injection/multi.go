@@ -0,0 +1,96 @@
+package dependency_injection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrMultipleDependencies is matched by the error of Any(...) when several dependencies
+// of the requested type are registered within a container whose MultiPolicy is Error.
+var ErrMultipleDependencies = errors.New("multiple dependencies found")
+
+// MultiPolicy describes which dependency a container resolves when several registered
+// within it match the requested type.
+type MultiPolicy int
+
+const (
+	// Last resolves the most recently added dependency, the default.
+	Last MultiPolicy = iota
+	// First resolves the dependency added first.
+	First
+	// Error resolves none of them: Any(...) returns an ErrMultipleDependencies error.
+	Error
+)
+
+// SetMultiPolicy sets which dependency the container resolves when several registered
+// within it match the requested type, see MultiPolicy. Dependencies registered under
+// the exact type key are preferred to those found by scanning all dependencies, whatever
+// the policy. Under Error, a container holding several matches is not fallen back from
+// to its parent, so TryAny(...) reports a miss and Any(...) names the ambiguous type.
+func (di *DependencyInjection) SetMultiPolicy(policy MultiPolicy) {
+	di.info.mutex.Lock()
+	di.info.multi = policy
+	di.info.publish()
+	di.info.mutex.Unlock()
+}
+
+// ambiguity is the dependency that find returns, without ok, when several dependencies
+// match under the Error policy, so that resolution stops instead of trying the parent.
+var ambiguity interface{} = &struct{ multiple bool }{true}
+
+// pick returns the dependency of the first bucket holding one that satisfies match,
+// chosen among those of the bucket by policy. Under Error, several distinct matches
+// within that bucket yield ambiguity.
+func pick(policy MultiPolicy, match func(dep interface{}) bool, buckets ...[]interface{}) (interface{}, bool) {
+	switch policy {
+	case First:
+		for _, deps := range buckets {
+			for i := range deps {
+				if match(deps[i]) {
+					return deps[i], true
+				}
+			}
+		}
+	case Error:
+		for _, deps := range buckets {
+			var found interface{}
+			var ok bool
+			for i := range deps {
+				if !match(deps[i]) {
+					continue
+				}
+				if ok && identity(deps[i]) != identity(found) {
+					return ambiguity, false
+				}
+				found, ok = deps[i], true
+			}
+			if ok {
+				return found, true
+			}
+		}
+	default:
+		for _, deps := range buckets {
+			for i := len(deps) - 1; i >= 0; i-- {
+				if match(deps[i]) {
+					return deps[i], true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// multiple returns an ErrMultipleDependencies error if the resolution of type key t
+// from the container or its parents stopped at one holding several dependencies of t.
+func (di *DependencyInjection) multiple(t reflect.Type) error {
+	var visited visitedSet
+	for c := di; c != nil && visited.add(c.info); c = c.Parent() {
+		if dep, ok := c.find(t, isType(t)); ok || c.factoryOf(t) != nil {
+			return nil
+		} else if dep == ambiguity {
+			return fmt.Errorf("%w: %v", ErrMultipleDependencies, t)
+		}
+	}
+	return nil
+}
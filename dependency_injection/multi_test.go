@@ -0,0 +1,78 @@
+package dependency_injection
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMultiPolicy(t *testing.T) {
+	for _, frozen := range []bool{false, true} {
+		for _, tc := range []struct {
+			name   string
+			policy MultiPolicy
+			want   string
+		}{
+			{"Last", Last, "second"},
+			{"First", First, "first"},
+			{"Error", Error, ""},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				di := NewDependencyInjection()
+				di.SetMultiPolicy(tc.policy)
+				di.Add(&testConfig{name: "first"})
+				di.Add(&testPerson{name: "first"})
+				di.Add(&testConfig{name: "second"})
+				di.Add(&testPerson{name: "second"})
+				if frozen {
+					di.Freeze()
+				}
+
+				var config *testConfig
+				var namer testNamer
+				errConfig, errNamer := Any(di, &config), Any(di, &namer)
+				if tc.want == "" {
+					if !errors.Is(errConfig, ErrMultipleDependencies) || !errors.Is(errNamer, ErrMultipleDependencies) {
+						t.Fatalf("Any of two = %v and %v, want ErrMultipleDependencies", errConfig, errNamer)
+					}
+					if _, ok := TryAny[*testConfig](di); ok {
+						t.Fatal("TryAny of two resolved one")
+					}
+					return
+				}
+				if errConfig != nil || config.name != tc.want {
+					t.Fatalf("Any of two *testConfig = %v, %v; want %q", config, errConfig, tc.want)
+				}
+				if errNamer != nil || namer.Name() != tc.want {
+					t.Fatalf("Any of two testNamer = %v, %v; want %q", namer, errNamer, tc.want)
+				}
+			})
+		}
+	}
+}
+
+func TestMultiPolicyErrorKeepsSingleMatches(t *testing.T) {
+	parent := NewDependencyInjection()
+	parent.Add(&testConfig{name: "parent"})
+	parent.SetMultiPolicy(Error)
+	child := NewChild(parent)
+
+	config := &testConfig{name: "child"}
+	child.Add(config)
+	AddAs[testNamer](child, &testPerson{name: "exact"})
+	child.Add(&testPerson{name: "scanned"})
+	if got, ok := TryAny[*testConfig](child); !ok || got != config {
+		t.Fatalf("Any of a single match = %v, want the child config", got)
+	}
+	if got := MustAny[testNamer](child); got.Name() != "exact" {
+		t.Fatalf("Any preferred %v to the dependency under the exact type key", got)
+	}
+
+	child.Add(&testConfig{name: "other"})
+	var got *testConfig
+	if err := Any(child, &got); !errors.Is(err, ErrMultipleDependencies) {
+		t.Fatalf("Any of two in the child = %v, %v; want ErrMultipleDependencies rather than the parent", got, err)
+	}
+	if got := All[*testConfig](child); len(got) != 3 {
+		t.Fatalf("All under the Error policy = %v, want all three", got)
+	}
+}
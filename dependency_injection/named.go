@@ -0,0 +1,111 @@
+package dependency_injection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrDuplicateName is returned by AddNamedUnique(...) when the name is already registered.
+var ErrDuplicateName = errors.New("name already registered")
+
+// ErrNamedType is returned by Named(...) and AnyByKey(...) when the name or key is
+// registered with a dependency that is not of the requested type.
+var ErrNamedType = errors.New("named dependency has another type")
+
+// AddNamed registers a dependency within the container under the given name.
+// Names live in their own keyspace, separate from the type-keyed dependencies,
+// and registering an existing name replaces the previous dependency.
+func (di *DependencyInjection) AddNamed(name string, dep interface{}) {
+	di.info.mutex.Lock()
+
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		return
+	}
+
+	if di.info.named == nil {
+		di.info.named = make(map[string]interface{})
+	}
+	di.info.named[name] = dep
+
+	di.info.mutex.Unlock()
+}
+
+// AddNamedUnique registers a dependency within the container under the given name
+// like AddNamed, unless the name is already registered within the container, in which
+// case the existing dependency is kept and an ErrDuplicateName error is returned.
+func (di *DependencyInjection) AddNamedUnique(name string, dep interface{}) error {
+	di.info.mutex.Lock()
+
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		return nil
+	}
+
+	if _, taken := di.info.named[name]; taken {
+		di.info.mutex.Unlock()
+		return fmt.Errorf("%w: %q", ErrDuplicateName, name)
+	}
+	if di.info.named == nil {
+		di.info.named = make(map[string]interface{})
+	}
+	di.info.named[name] = dep
+
+	di.info.mutex.Unlock()
+	return nil
+}
+
+// RemoveNamed unregisters the dependency registered under the given name.
+func (di *DependencyInjection) RemoveNamed(name string) {
+	di.info.mutex.Lock()
+
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		return
+	}
+
+	delete(di.info.named, name)
+
+	di.info.mutex.Unlock()
+}
+
+// Named retrieves the dependency of type T registered under the given name,
+// falling back to the parent container if the name is not registered. A name registered
+// with a dependency of another type returns ErrNamedType instead of looking further.
+func Named[T any](di *DependencyInjection, name string) (result T, err error) {
+	dep, err := di.named(name, keyFor[T]())
+	if err == nil {
+		result = (dep).(T)
+	}
+	return
+}
+
+// named retrieves the dependency of type key t registered under the given name, the
+// untyped core of Named(...).
+func (di *DependencyInjection) named(name string, t reflect.Type) (interface{}, error) {
+	var visited visitedSet
+	for ; di != nil && visited.add(di.info); di = di.Parent() {
+		di.info.mutex.RLock()
+		dep, found := di.info.named[name]
+		di.info.mutex.RUnlock()
+
+		if found {
+			if isType(t)(dep) {
+				return dep, nil
+			}
+			return nil, fmt.Errorf("%w: %q is %v, not %s", ErrNamedType, name, typeKey(dep), t)
+		}
+	}
+	return nil, fmt.Errorf("%w: %q of type %s", ErrDependencyNotFound, name, t)
+}
+
+// MustNamed retrieves the dependency of type T registered under the given name like
+// Named(...), panicking with the name and type if it is missing or not of type T.
+func MustNamed[T any](di *DependencyInjection, name string) T {
+	result, err := Named[T](di, name)
+	if err != nil {
+		panic(err.Error())
+	}
+	return result
+}
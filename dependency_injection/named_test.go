@@ -0,0 +1,111 @@
+package dependency_injection
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNamed(t *testing.T) {
+	di := NewDependencyInjection()
+	di.AddNamed("primary", "db1")
+	di.AddNamed("replica", "db2")
+
+	if got, err := Named[string](di, "replica"); err != nil || got != "db2" {
+		t.Fatalf("Named = %q, %v; want db2", got, err)
+	}
+	if _, err := Named[string](di, "missing"); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("Named of a missing name = %v, want ErrDependencyNotFound", err)
+	}
+}
+
+func TestNamedWrongTypeStopsAtFirstContainer(t *testing.T) {
+	parent := NewDependencyInjection()
+	parent.AddNamed("port", 8080)
+	child := NewChild(parent)
+	child.AddNamed("port", "8080")
+
+	_, err := Named[int](child, "port")
+	if !errors.Is(err, ErrNamedType) || errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("Named with the wrong type = %v, want ErrNamedType", err)
+	}
+	if !strings.Contains(err.Error(), "string") || !strings.Contains(err.Error(), "int") {
+		t.Fatalf("error %q does not name both types", err)
+	}
+}
+
+func TestNamedFallsBackToParent(t *testing.T) {
+	parent := NewDependencyInjection()
+	parent.AddNamed("port", 8080)
+	child := NewChild(parent)
+
+	if got, err := Named[int](child, "port"); err != nil || got != 8080 {
+		t.Fatalf("Named from the child = %v, %v; want the parent's 8080", got, err)
+	}
+}
+
+func TestAddNamedUnique(t *testing.T) {
+	di := NewDependencyInjection()
+	if err := di.AddNamedUnique("primary", "db1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := di.AddNamedUnique("primary", "db2"); !errors.Is(err, ErrDuplicateName) {
+		t.Fatalf("AddNamedUnique of a taken name = %v, want ErrDuplicateName", err)
+	}
+	if got := MustNamed[string](di, "primary"); got != "db1" {
+		t.Fatalf("MustNamed = %q, want the first db1 kept", got)
+	}
+}
+
+func TestMustNamedPanicNamesDependency(t *testing.T) {
+	di := NewDependencyInjection()
+
+	v := panicOf(t, func() { MustNamed[int](di, "port") })
+	if msg, _ := v.(string); !strings.Contains(msg, `"port"`) || !strings.Contains(msg, "int") {
+		t.Fatalf("MustNamed panic = %v, want the name and type", v)
+	}
+}
+
+func TestRemoveNamed(t *testing.T) {
+	di := NewDependencyInjection()
+	di.AddNamed("primary", "db1")
+	di.RemoveNamed("primary")
+
+	if _, err := Named[string](di, "primary"); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("Named after RemoveNamed = %v, want ErrDependencyNotFound", err)
+	}
+}
+
+func TestAddNamedOverwrites(t *testing.T) {
+	di := NewDependencyInjection()
+	di.AddNamed("primary", "db1")
+	di.AddNamed("primary", "db2")
+
+	if got := MustNamed[string](di, "primary"); got != "db2" {
+		t.Fatalf("MustNamed after overwriting = %q, want db2", got)
+	}
+	if err := di.AddNamedUnique("primary", "db3"); !errors.Is(err, ErrDuplicateName) || !strings.Contains(err.Error(), `"primary"`) {
+		t.Fatalf("AddNamedUnique = %v, want ErrDuplicateName naming the name", err)
+	}
+	if err := NewChild(di).AddNamedUnique("primary", "db3"); err != nil {
+		t.Fatalf("AddNamedUnique in a child = %v, want the parent's name not to count", err)
+	}
+}
+
+func TestMustNamedHit(t *testing.T) {
+	di := NewDependencyInjection()
+	di.AddNamed("port", 8080)
+	if got := MustNamed[int](di, "port"); got != 8080 {
+		t.Fatalf("MustNamed = %d, want 8080", got)
+	}
+}
+
+func TestMustNamedWrongType(t *testing.T) {
+	di := NewDependencyInjection()
+	di.AddNamed("port", "8080")
+
+	v := panicOf(t, func() { MustNamed[int](di, "port") })
+	if msg, _ := v.(string); !strings.Contains(msg, `"port"`) || !strings.Contains(msg, "string") {
+		t.Fatalf("MustNamed with the wrong type panicked with %v, want the name and both types", v)
+	}
+}
@@ -0,0 +1,46 @@
+package dependency_injection
+
+import (
+	"math"
+	"reflect"
+	"runtime"
+)
+
+// Option configures a container made by NewDependencyInjection(...).
+type Option func(di *DependencyInjection)
+
+// WithLifetime sets the lifetime of the container: Transient makes it transient as with
+// SetTransient(true), and Pooled gives it the pool of NewPooledDependencyInjection(...).
+func WithLifetime(lifetime Lifetime) Option {
+	return func(di *DependencyInjection) {
+		di.lifetime = lifetime
+		switch lifetime {
+		case Transient:
+			di.SetTransient(true)
+		case Pooled:
+			di.info.pool = &pool{max: math.MaxInt, idle: runtime.GOMAXPROCS(0), objects: make(map[reflect.Type]*objectPool)}
+		}
+	}
+}
+
+// WithMaxDepth sets the maximum nesting of constructors, as with SetMaxDepth(...).
+func WithMaxDepth(depth int) Option {
+	return func(di *DependencyInjection) {
+		di.SetMaxDepth(depth)
+	}
+}
+
+// WithInterfaceScan sets whether interfaces resolve to concrete types added with Add,
+// as with EnableInterfaceScan(...).
+func WithInterfaceScan(enable bool) Option {
+	return func(di *DependencyInjection) {
+		di.EnableInterfaceScan(enable)
+	}
+}
+
+// WithResolveHook registers a hook called after each lookup, as with OnResolve(...).
+func WithResolveHook(hook func(typeName string, found bool)) Option {
+	return func(di *DependencyInjection) {
+		di.OnResolve(hook)
+	}
+}
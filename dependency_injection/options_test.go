@@ -0,0 +1,78 @@
+package dependency_injection
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithLifetimeTransient(t *testing.T) {
+	di := NewDependencyInjection(WithLifetime(Transient))
+	if di.Lifetime() != Transient || !di.IsTransient() {
+		t.Fatalf("Lifetime() = %v, want Transient", di.Lifetime())
+	}
+
+	var calls int
+	newer := func(*DependencyInjection) *lifetimeCounter {
+		calls++
+		return &lifetimeCounter{n: calls}
+	}
+	MustNew(di, newer)
+	MustNew(di, newer)
+	if calls != 2 {
+		t.Fatalf("MustNew made %d objects, want 2", calls)
+	}
+}
+
+func TestWithLifetimePooled(t *testing.T) {
+	di := NewDependencyInjection(WithLifetime(Pooled))
+	if di.Lifetime() != Pooled {
+		t.Fatalf("Lifetime() = %v, want Pooled", di.Lifetime())
+	}
+
+	var calls int
+	newer := func(*DependencyInjection) *pooledConn {
+		calls++
+		return &pooledConn{id: calls}
+	}
+	conn := Acquire(di, newer)
+	Release(di, conn)
+	if again := Acquire(di, newer); again != conn || calls != 1 {
+		t.Fatalf("Acquire after Release = %v with %d objects made, want the released object", again, calls)
+	}
+}
+
+func TestWithMaxDepth(t *testing.T) {
+	di := NewDependencyInjection(WithMaxDepth(1))
+
+	_, err := Need(di, func(di *DependencyInjection) (*factoryA, error) {
+		b, err := Need(di, func(*DependencyInjection) (*factoryB, error) { return &factoryB{}, nil })
+		return &factoryA{b: &b}, err
+	})
+	if !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Fatalf("Need nested two deep = %v, want ErrMaxDepthExceeded", err)
+	}
+}
+
+func TestWithInterfaceScan(t *testing.T) {
+	di := NewDependencyInjection(WithInterfaceScan(false))
+	di.Add(&testMemoryStore{})
+
+	if _, ok := TryAny[testStore](di); ok {
+		t.Fatal("interface resolved to a concrete type with the interface scan disabled")
+	}
+	if _, ok := TryAny[*testMemoryStore](di); !ok {
+		t.Fatal("concrete type not found with the interface scan disabled")
+	}
+}
+
+func TestWithResolveHook(t *testing.T) {
+	var names []string
+	di := NewDependencyInjection(WithResolveHook(func(typeName string, found bool) {
+		names = append(names, typeName)
+	}))
+	var config *testConfig
+	_ = Any(di, &config)
+	if len(names) != 1 || names[0] != "*dependency_injection.testConfig" {
+		t.Fatalf("hook saw %v, want one lookup of *dependency_injection.testConfig", names)
+	}
+}
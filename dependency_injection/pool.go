@@ -0,0 +1,177 @@
+package dependency_injection
+
+import (
+	"io"
+	"reflect"
+	"sync"
+)
+
+// pool holds, per type, up to idle objects waiting to be acquired, out of at most max made.
+type pool struct {
+	min     int
+	max     int
+	idle    int
+	objects map[reflect.Type]*objectPool
+	mutex   sync.Mutex
+}
+
+type objectPool struct {
+	idle    chan interface{}
+	created int
+}
+
+// Acquire checks an object of type T out of the pool of a sized pooled container,
+// making it using the given constructor function while the pool is below its maximum
+// size, and blocking until an object is released otherwise. The first Acquire of a type
+// also makes the minimum number of idle objects. On other containers it behaves like MustNeed.
+func Acquire[T any](di *DependencyInjection, newer func(di *DependencyInjection) *T) T {
+	var p = di.info.pool
+	if p == nil {
+		return MustNeed(di, newer)
+	}
+	return p.acquire(p.of(keyFor[T]()), func() interface{} {
+		return construct(di, newer)
+	}).(T)
+}
+
+// Release returns an object of type T previously checked out by Acquire to the pool.
+// An object that does not fit among the idle objects of the pool is dropped instead,
+// closing it if it implements io.Closer.
+func Release[T any](di *DependencyInjection, obj T) {
+	var p = di.info.pool
+	if p == nil {
+		return
+	}
+	p.release(p.of(keyFor[T]()), obj)
+}
+
+// Rent resolves a dependency of type T for the caller to use until it calls the returned
+// release function, which only has an effect the first time it is called. In a pooled
+// container, the object is checked out of the pool as with Acquire(...), made by the factory
+// registered for T, and release returns it to the pool. Elsewhere T is resolved as with
+// Any(...), and release closes an object made for the rental by a Transient factory if it
+// implements io.Closer; other objects remain owned by the container.
+func Rent[T any](di *DependencyInjection) (T, func(), error) {
+	var result T
+	var t0 = keyFor[T]()
+	var f = di.factoryFor(t0)
+
+	if p := di.info.pool; p != nil && f != nil {
+		var objects = p.of(t0)
+		result = p.acquire(objects, func() interface{} {
+			return decorate(di, t0, produce(di, di, &factory{lifetime: Transient, build: f.build}, t0))
+		}).(T)
+		var once sync.Once
+		return result, func() { once.Do(func() { p.release(objects, result) }) }, nil
+	}
+
+	if err := Any(di, &result); err != nil {
+		return result, func() {}, err
+	}
+	if closer, ok := interface{}(result).(io.Closer); ok && f != nil && f.lifetime == Transient {
+		var once sync.Once
+		return result, func() { once.Do(func() { _ = closer.Close() }) }, nil
+	}
+	return result, func() {}, nil
+}
+
+// factoryFor returns the factory that resolving type key t0 in the container would run:
+// the first factory for t0 of the container and its parents, unless a dependency of type
+// t0 is registered before it. It returns nil if there is none.
+func (di *DependencyInjection) factoryFor(t0 reflect.Type) *factory {
+	var visited visitedSet
+	for ; di != nil && visited.add(di.info); di = di.Parent() {
+		if _, ok := di.find(t0, isType(t0)); ok {
+			return nil
+		}
+		if f := di.factoryOf(t0); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// acquire checks an object out of the pool objects, making it with newer while the pool
+// is below its maximum size and blocking until an object is released otherwise.
+func (p *pool) acquire(objects *objectPool, newer func() interface{}) interface{} {
+	for p.reserve(objects, p.min) {
+		objects.idle <- p.fill(objects, newer)
+	}
+	select {
+	case obj := <-objects.idle:
+		return obj
+	default:
+	}
+	if p.reserve(objects, p.max) {
+		return p.fill(objects, newer)
+	}
+	return <-objects.idle
+}
+
+// release returns obj to the pool objects, or drops it, closing it if it implements
+// io.Closer, when the idle objects are full.
+func (p *pool) release(objects *objectPool, obj interface{}) {
+	select {
+	case objects.idle <- obj:
+	default:
+		p.mutex.Lock()
+		objects.created--
+		p.mutex.Unlock()
+		if closer, ok := obj.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}
+}
+
+// fill makes an object for a slot reserved with reserve, giving the slot back
+// if the constructor panics.
+func (p *pool) fill(objects *objectPool, newer func() interface{}) interface{} {
+	var made bool
+	defer func() {
+		if !made {
+			p.mutex.Lock()
+			objects.created--
+			p.mutex.Unlock()
+		}
+	}()
+	obj := newer()
+	made = true
+	return obj
+}
+
+// of returns the pool of objects with type key t, creating it on first use.
+func (p *pool) of(t reflect.Type) *objectPool {
+	p.mutex.Lock()
+	objects := p.objects[t]
+	if objects == nil {
+		objects = &objectPool{idle: make(chan interface{}, p.idle)}
+		p.objects[t] = objects
+	}
+	p.mutex.Unlock()
+	return objects
+}
+
+// reserve counts one more object as created, if fewer than limit objects exist.
+func (p *pool) reserve(objects *objectPool, limit int) bool {
+	p.mutex.Lock()
+	ok := objects.created < limit
+	if ok {
+		objects.created++
+	}
+	p.mutex.Unlock()
+	return ok
+}
+
+// drain removes the idle objects from every pool and returns them, so the pool makes
+// new ones in their place. Objects checked out are kept and can still be released.
+func (p *pool) drain() (objects []interface{}) {
+	p.mutex.Lock()
+	for _, pooled := range p.objects {
+		for n := len(pooled.idle); n > 0; n-- {
+			objects = append(objects, <-pooled.idle)
+			pooled.created--
+		}
+	}
+	p.mutex.Unlock()
+	return
+}
@@ -0,0 +1,226 @@
+package dependency_injection
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+type pooledConn struct {
+	id int
+}
+
+func TestAcquireMakesMinOnFirstAcquire(t *testing.T) {
+	pooled := NewPooledDependencyInjectionSized(NewDependencyInjection(), 2, 4)
+
+	var calls int
+	newer := func(*DependencyInjection) *pooledConn {
+		calls++
+		return &pooledConn{id: calls}
+	}
+	if calls != 0 {
+		t.Fatalf("%d objects made before the first Acquire", calls)
+	}
+	Acquire(pooled, newer)
+	if calls != 2 {
+		t.Fatalf("first Acquire made %d objects, want the min of 2", calls)
+	}
+}
+
+func TestAcquireReusesReleased(t *testing.T) {
+	pooled := NewPooledDependencyInjectionSized(NewDependencyInjection(), 0, 1)
+
+	var calls int
+	newer := func(*DependencyInjection) *pooledConn {
+		calls++
+		return &pooledConn{id: calls}
+	}
+	conn := Acquire(pooled, newer)
+	Release(pooled, conn)
+	if again := Acquire(pooled, newer); again != conn || calls != 1 {
+		t.Fatalf("Acquire after Release = %v with %d objects made, want the released object", again, calls)
+	}
+}
+
+func TestAcquireBlocksAtMax(t *testing.T) {
+	pooled := NewPooledDependencyInjectionSized(NewDependencyInjection(), 0, 1)
+	newer := func(*DependencyInjection) *pooledConn { return &pooledConn{} }
+
+	conn := Acquire(pooled, newer)
+	acquired := make(chan pooledConn)
+	go func() { acquired <- Acquire(pooled, newer) }()
+
+	select {
+	case <-acquired:
+		t.Fatal("Acquire did not block with max objects checked out")
+	case <-time.After(20 * time.Millisecond):
+	}
+	Release(pooled, conn)
+	if got := <-acquired; got != conn {
+		t.Fatalf("blocked Acquire got %v, want the released object", got)
+	}
+}
+
+func TestAcquirePanicGivesSlotBack(t *testing.T) {
+	pooled := NewPooledDependencyInjectionSized(NewDependencyInjection(), 0, 1)
+
+	func() {
+		defer func() { recover() }()
+		Acquire(pooled, func(*DependencyInjection) *pooledConn { panic("dial failed") })
+	}()
+
+	done := make(chan pooledConn)
+	go func() {
+		done <- Acquire(pooled, func(*DependencyInjection) *pooledConn { return &pooledConn{id: 1} })
+	}()
+	select {
+	case got := <-done:
+		if got.id != 1 {
+			t.Fatalf("Acquire = %v, want a new object", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Acquire blocked after a panicking constructor used up the pool")
+	}
+}
+
+func TestAcquireOnOtherContainers(t *testing.T) {
+	di := NewDependencyInjection()
+
+	var calls int
+	newer := func(*DependencyInjection) *pooledConn {
+		calls++
+		return &pooledConn{id: calls}
+	}
+	Acquire(di, newer)
+	Acquire(di, newer)
+	if calls != 1 {
+		t.Fatalf("Acquire outside a sized pool made %d objects, want 1 like MustNeed", calls)
+	}
+}
+
+type closingConn struct {
+	closed *int
+}
+
+func (c closingConn) Close() error {
+	*c.closed++
+	return nil
+}
+
+func TestPooledAcquireDoesNotBlock(t *testing.T) {
+	pooled := NewPooledDependencyInjection(NewDependencyInjection())
+	newer := func(*DependencyInjection) *pooledConn { return &pooledConn{} }
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < runtime.GOMAXPROCS(0)+2; i++ {
+			Acquire(pooled, newer)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Acquire blocked on a pool without a size")
+	}
+}
+
+func TestPooledReclaimIsDeterministic(t *testing.T) {
+	pooled := NewPooledDependencyInjection(NewDependencyInjection())
+
+	var closed int
+	newer := func(*DependencyInjection) *closingConn { return &closingConn{closed: &closed} }
+
+	var n = runtime.GOMAXPROCS(0) + 2
+	var conns []closingConn
+	for i := 0; i < n; i++ {
+		conns = append(conns, Acquire(pooled, newer))
+	}
+	for _, conn := range conns {
+		Release(pooled, conn)
+	}
+	if closed != 2 {
+		t.Fatalf("Release closed %d objects, want the 2 that do not fit among the idle ones", closed)
+	}
+	if err := pooled.Dispose(); err != nil {
+		t.Fatal(err)
+	}
+	if closed != n {
+		t.Fatalf("Dispose left %d of %d objects open", n-closed, n)
+	}
+}
+
+func TestRentBlocksUntilRelease(t *testing.T) {
+	parent := NewDependencyInjection()
+	var calls int
+	AddFactory(parent, func(*DependencyInjection) *pooledConn {
+		calls++
+		return &pooledConn{id: calls}
+	})
+	pooled := NewPooledDependencyInjectionSized(parent, 0, 1)
+
+	conn, release, err := Rent[*pooledConn](pooled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rented := make(chan *pooledConn)
+	go func() {
+		again, _, _ := Rent[*pooledConn](pooled)
+		rented <- again
+	}()
+	select {
+	case <-rented:
+		t.Fatal("Rent did not block with the pool exhausted")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release()
+	release()
+	if got := <-rented; got != conn || calls != 1 {
+		t.Fatalf("blocked Rent got %v with %d objects made, want the released object", got, calls)
+	}
+	go func() {
+		again, _, _ := Rent[*pooledConn](pooled)
+		rented <- again
+	}()
+	select {
+	case got := <-rented:
+		t.Fatalf("Rent got %v after a second release, which returned the object twice", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestRentTransientClosesOnRelease(t *testing.T) {
+	di := NewDependencyInjection()
+	var closed int
+	AddTransient(di, func(*DependencyInjection) closingConn { return closingConn{closed: &closed} })
+
+	_, release, err := Rent[closingConn](di)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	release()
+	if closed != 1 {
+		t.Fatalf("release closed the Transient object %d times, want 1", closed)
+	}
+}
+
+func TestRentRegisteredKeepsObject(t *testing.T) {
+	di := NewDependencyInjection()
+	var closed int
+	di.Add(closingConn{closed: &closed})
+
+	_, release, err := Rent[closingConn](di)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if closed != 0 {
+		t.Fatal("release closed an object owned by the container")
+	}
+	if _, _, err := Rent[*pooledConn](di); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("Rent of a missing type = %v, want ErrDependencyNotFound", err)
+	}
+}
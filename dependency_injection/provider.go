@@ -0,0 +1,22 @@
+package dependency_injection
+
+import (
+	"errors"
+)
+
+// Provider registers the services of a module within a container.
+type Provider interface {
+	Register(di *DependencyInjection) error
+}
+
+// Install calls Register of each provider in order. A failing provider does not stop
+// the others; the errors of all failing providers are returned joined.
+func (di *DependencyInjection) Install(providers ...Provider) error {
+	var errs []error
+	for _, p := range providers {
+		if err := p.Register(di); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,35 @@
+package dependency_injection
+
+import (
+	"errors"
+	"testing"
+)
+
+type providerFunc func(di *DependencyInjection) error
+
+func (f providerFunc) Register(di *DependencyInjection) error { return f(di) }
+
+func TestInstallSurfacesErrorAndRunsOthers(t *testing.T) {
+	di := NewDependencyInjection()
+	errStorage := errors.New("storage unavailable")
+
+	err := di.Install(
+		providerFunc(func(di *DependencyInjection) error { return errStorage }),
+		providerFunc(func(di *DependencyInjection) error {
+			di.Add(&testConfig{name: "web"})
+			return nil
+		}),
+	)
+	if !errors.Is(err, errStorage) {
+		t.Fatalf("Install = %v, want the failing provider's error", err)
+	}
+	if _, ok := TryAny[*testConfig](di); !ok {
+		t.Fatal("provider after the failing one did not run")
+	}
+}
+
+func TestInstallNoProviders(t *testing.T) {
+	if err := NewDependencyInjection().Install(); err != nil {
+		t.Fatalf("Install() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,184 @@
+package dependency_injection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrCircularDependency is raised by MustNeed(...) and returned by Need(...) when constructors
+// depend on each other in a cycle.
+var ErrCircularDependency = errors.New("circular dependency")
+
+// ErrMaxDepthExceeded is raised by MustNeed(...) and returned by Need(...) when constructors
+// are nested deeper than the maximum set with SetMaxDepth(...).
+var ErrMaxDepthExceeded = errors.New("maximum dependency resolution depth exceeded")
+
+// defaultMaxDepth is the maximum nesting of constructors unless set with SetMaxDepth(...).
+const defaultMaxDepth = 100
+
+// resolution records a type under construction, linked to the construction
+// that requested it. A resolution of a flight marks where constructions
+// started by the flight begin, without being a construction itself, as does
+// a resolution carrying the context of AnyContext(...), which is done from the start.
+type resolution struct {
+	t      reflect.Type
+	parent *resolution
+	depth  int
+	done   int32
+	flight *flight
+	ctx    context.Context
+}
+
+// contextOf returns the context of the AnyContext(...) call that the resolution r
+// continues, or context.Background() if there is none.
+func (r *resolution) contextOf() context.Context {
+	for ; r != nil; r = r.parent {
+		if r.ctx != nil {
+			return r.ctx
+		}
+	}
+	return context.Background()
+}
+
+// SetMaxDepth sets how deeply constructors may be nested within one another before the
+// resolution fails with ErrMaxDepthExceeded. A depth of zero or less restores the default of 100.
+func (di *DependencyInjection) SetMaxDepth(depth int) {
+	di.info.mutex.Lock()
+	di.info.maxDepth = depth
+	di.info.mutex.Unlock()
+}
+
+// maxDepthOf returns the maximum nesting of constructors within the container.
+func (di *DependencyInjection) maxDepthOf() int {
+	di.info.mutex.RLock()
+	depth := di.info.maxDepth
+	di.info.mutex.RUnlock()
+	if depth <= 0 {
+		return defaultMaxDepth
+	}
+	return depth
+}
+
+// Initializable is implemented by dependencies that need a second phase of
+// initialization after construction. Init is called once the dependency is made by
+// MustNeed(...), Need(...), a factory or Build(...), before it is cached, with a handle
+// on the container resolving it. An error fails the construction: Need(...) and Build(...)
+// return it, while MustNeed(...) and factories panic with it.
+type Initializable interface {
+	Init(di *DependencyInjection) error
+}
+
+// initialize calls Init of dep if it is Initializable.
+func initialize(di *DependencyInjection, dep interface{}) error {
+	if init, ok := dep.(Initializable); ok {
+		return init.Init(di)
+	}
+	return nil
+}
+
+// construct makes a dependency of type T using the given constructor function. The
+// constructor receives a handle on the same container that remembers T is under
+// construction, so a constructor requesting T again, directly or through other
+// constructors, panics with the cycle instead of overflowing the stack. Panics raised
+// by constructors are annotated with the types under construction and re-raised.
+func construct[T any](di *DependencyInjection, newer func(di *DependencyInjection) *T) T {
+	result, err := constructErr(di, func(di *DependencyInjection) (*T, error) {
+		return newer(di), nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// constructErr is like construct for constructor functions that can fail. Errors,
+// including a cycle, are returned annotated with the types under construction.
+func constructErr[T any](di *DependencyInjection, newer func(di *DependencyInjection) (*T, error)) (result T, err error) {
+	dep, err := di.constructAs(keyFor[T](), func(di *DependencyInjection) (interface{}, error) {
+		ptr, err := newer(di)
+		if err != nil {
+			return nil, err
+		}
+		if err := initialize(di, ptr); err != nil {
+			return nil, err
+		}
+		return *ptr, nil
+	})
+	result, _ = (dep).(T)
+	return result, err
+}
+
+// constructAs runs newer as the construction of type key t along the resolution of di,
+// passing it a handle that remembers t is under construction. It is the untyped core of
+// constructErr, also used to invoke factories.
+func (di *DependencyInjection) constructAs(t reflect.Type, newer func(di *DependencyInjection) (interface{}, error)) (dep interface{}, err error) {
+	r := &resolution{t: t, parent: di.path, depth: 1}
+	if di.path != nil {
+		r.depth = di.path.depth + 1
+	}
+	if di.path.building(t) {
+		return nil, &constructionError{path: r.String(), value: ErrCircularDependency}
+	}
+	if r.depth > di.maxDepthOf() {
+		return nil, &constructionError{path: r.String(), value: ErrMaxDepthExceeded}
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			if _, ok := v.(*constructionError); !ok {
+				v = &constructionError{path: r.String(), value: v}
+			}
+			atomic.StoreInt32(&r.done, 1)
+			panic(v)
+		}
+		atomic.StoreInt32(&r.done, 1)
+	}()
+
+	dep, err = newer(di.along(r))
+	if err != nil {
+		if _, ok := err.(*constructionError); !ok {
+			err = &constructionError{path: r.String(), value: err}
+		}
+		return nil, err
+	}
+	return dep, nil
+}
+
+// along returns a handle on the container of di that continues the resolution r.
+func (di *DependencyInjection) along(r *resolution) *DependencyInjection {
+	return &DependencyInjection{info: di.info, lifetime: di.lifetime, path: r}
+}
+
+// constructionError annotates a panic or error raised while constructing a dependency
+// with the chain of types under construction.
+type constructionError struct {
+	path  string
+	value interface{}
+}
+
+func (e *constructionError) Error() string {
+	return fmt.Sprintf("%v (constructing %s)", e.value, e.path)
+}
+
+func (e *constructionError) Unwrap() error {
+	err, _ := (e.value).(error)
+	return err
+}
+
+// String returns the chain of types under construction, outermost first.
+func (r *resolution) String() string {
+	var names []string
+	for ; r != nil; r = r.parent {
+		if r.flight == nil && atomic.LoadInt32(&r.done) == 0 {
+			names = append(names, r.t.String())
+		}
+	}
+	for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+		names[i], names[j] = names[j], names[i]
+	}
+	return strings.Join(names, " -> ")
+}
@@ -0,0 +1,272 @@
+package dependency_injection
+
+import (
+	"reflect"
+)
+
+// Snapshot captures the registrations of the container and returns a function that
+// restores them, discarding everything registered or removed in between. The maps
+// of the container are copied, the dependencies themselves are shared. Parent
+// containers are not captured, and the restore function can be called repeatedly.
+func (di *DependencyInjection) Snapshot() (restore func()) {
+	di.info.mutex.RLock()
+	saved := di.info.copyRegistrations()
+	di.info.mutex.RUnlock()
+
+	return func() {
+		di.info.mutex.Lock()
+
+		if !di.info.writable() {
+			di.info.mutex.Unlock()
+			return
+		}
+
+		state := saved.copyRegistrations()
+		di.info.dependencies = state.dependencies
+		di.info.order = state.order
+		di.info.named = state.named
+		di.info.keyed = state.keyed
+		di.info.groups = state.groups
+		di.info.factories = state.factories
+		di.info.decorators = state.decorators
+		di.info.cleanups = state.cleanups
+		di.info.implementers = nil
+
+		di.info.mutex.Unlock()
+	}
+}
+
+// WithOverrides runs f with each of the overrides registered within the container in
+// place of the dependencies registered under its type, or under an interface it
+// implements as with AddAs(...), so a mock implementing an interface overrides the
+// service registered for it. The replaced dependencies are restored afterwards, even if
+// f panics. Only the buckets holding the replaced dependencies and the overrides are
+// restored: other registrations made or removed by f are kept. Transient containers,
+// which ignore registrations, run f unchanged.
+func WithOverrides(di *DependencyInjection, overrides []interface{}, f func()) {
+	for _, dep := range overrides {
+		checkIdentity(dep)
+	}
+
+	di.info.mutex.Lock()
+
+	if !di.info.writable() {
+		di.info.mutex.Unlock()
+		f()
+		return
+	}
+
+	var ids = make(map[interface{}]bool)
+	var keys = make(map[interface{}][]reflect.Type)
+	for _, dep := range overrides {
+		if dep == nil {
+			continue
+		}
+		ids[identity(dep)] = true
+		var t = typeKey(dep)
+		keys[identity(dep)] = append(keys[identity(dep)], t)
+		for k := range di.info.order {
+			if k != globalKey && k != t && k.Kind() == reflect.Interface && t.Implements(k) {
+				keys[identity(dep)] = append(keys[identity(dep)], k)
+			}
+		}
+	}
+	var replaced []interface{}
+	for _, ks := range keys {
+		for _, k := range ks {
+			for _, dep := range di.info.order[k] {
+				if !ids[identity(dep)] {
+					ids[identity(dep)] = true
+					replaced = append(replaced, dep)
+				}
+			}
+		}
+	}
+	var overridden = func(dep interface{}) bool {
+		return ids[identity(dep)]
+	}
+
+	var saved = di.info.copyBuckets(overridden)
+	for _, dep := range replaced {
+		di.info.unregister(dep)
+	}
+	for _, dep := range overrides {
+		if dep == nil {
+			continue
+		}
+		for _, k := range keys[identity(dep)] {
+			di.info.register(k, dep)
+		}
+	}
+
+	di.info.mutex.Unlock()
+
+	defer func() {
+		di.info.mutex.Lock()
+		di.info.restoreBuckets(saved, overridden)
+		di.info.mutex.Unlock()
+	}()
+	f()
+}
+
+// buckets holds the buckets of a container that hold dependencies satisfying some
+// condition, and the cleanups of those dependencies.
+type buckets struct {
+	order    map[reflect.Type][]interface{}
+	cleanups map[interface{}][]func() error
+}
+
+// copyBuckets returns a copy of every bucket of info holding a dependency satisfying
+// match, with the cleanups of those dependencies.
+func (info *dependencyInjection) copyBuckets(match func(dep interface{}) bool) *buckets {
+	var b = &buckets{order: make(map[reflect.Type][]interface{}), cleanups: make(map[interface{}][]func() error)}
+	for t, deps := range info.order {
+		for _, dep := range deps {
+			if match(dep) {
+				b.order[t] = append([]interface{}(nil), deps...)
+				break
+			}
+		}
+	}
+	for _, deps := range b.order {
+		for _, dep := range deps {
+			if fns, ok := info.cleanups[identity(dep)]; ok && match(dep) {
+				b.cleanups[identity(dep)] = append([]func() error(nil), fns...)
+			}
+		}
+	}
+	return b
+}
+
+// restoreBuckets puts back the dependencies satisfying match as saved by copyBuckets,
+// in their saved places, replacing those registered since. Dependencies not satisfying
+// match are left as they are now: those still registered keep their saved places, and
+// those registered since follow.
+func (info *dependencyInjection) restoreBuckets(saved *buckets, match func(dep interface{}) bool) {
+	var keys = make(map[reflect.Type]bool)
+	for t := range saved.order {
+		keys[t] = true
+	}
+	for t, deps := range info.order {
+		for _, dep := range deps {
+			if match(dep) {
+				keys[t] = true
+				break
+			}
+		}
+	}
+
+	for t := range keys {
+		var current = info.dependencies[t]
+		var was = make(map[interface{}]struct{}, len(saved.order[t]))
+		var deps []interface{}
+		for _, dep := range saved.order[t] {
+			was[identity(dep)] = struct{}{}
+			if _, ok := current[identity(dep)]; ok || match(dep) {
+				deps = append(deps, dep)
+			}
+		}
+		for _, dep := range info.order[t] {
+			if _, ok := was[identity(dep)]; !ok && !match(dep) {
+				deps = append(deps, dep)
+			}
+		}
+
+		for _, dep := range info.order[t] {
+			if match(dep) {
+				delete(info.cleanups, identity(dep))
+			}
+		}
+		if len(deps) == 0 {
+			delete(info.dependencies, t)
+			delete(info.order, t)
+			continue
+		}
+		var set = make(map[interface{}]struct{}, len(deps))
+		for _, dep := range deps {
+			set[identity(dep)] = struct{}{}
+		}
+		info.dependencies[t] = set
+		info.order[t] = deps
+	}
+	for id, fns := range saved.cleanups {
+		if info.cleanups == nil {
+			info.cleanups = make(map[interface{}][]func() error)
+		}
+		info.cleanups[id] = fns
+	}
+	info.implementers = nil
+}
+
+// Clone returns a new container holding the same registrations as the container, with
+// the same lifetime, settings, hooks, middlewares, federation and parent, whose
+// registrations can then change independently. As with Snapshot(), the dependencies
+// themselves are shared, while a pooled container gets an empty pool of the same size.
+func (di *DependencyInjection) Clone() *DependencyInjection {
+	di.info.mutex.RLock()
+
+	var info = di.info.copyRegistrations()
+	info.noInterfaceScan = di.info.noInterfaceScan
+	info.maxDepth = di.info.maxDepth
+	info.strict = di.info.strict
+	info.rejectZero = di.info.rejectZero
+	info.multi = di.info.multi
+	info.debug.Store(di.info.debug.Load())
+	info.hooks = append([](func(typeName string, found bool))(nil), di.info.hooks...)
+	info.middlewares = append([](func(next ResolveFunc) ResolveFunc)(nil), di.info.middlewares...)
+	info.federated = append([]*DependencyInjection(nil), di.info.federated...)
+	info.parent = di.info.parent
+	info.shared = di.info.shared
+	info.transient = di.info.transient
+	if p := di.info.pool; p != nil {
+		info.pool = &pool{min: p.min, max: p.max, idle: p.idle, objects: make(map[reflect.Type]*objectPool)}
+	}
+
+	di.info.mutex.RUnlock()
+
+	return &DependencyInjection{info: info, lifetime: di.lifetime}
+}
+
+// copyRegistrations returns a copy of the registration maps of info, sharing the
+// dependencies, factories and functions they hold.
+func (info *dependencyInjection) copyRegistrations() *dependencyInjection {
+	var c = &dependencyInjection{
+		dependencies: make(map[reflect.Type]map[interface{}]struct{}, len(info.dependencies)),
+		order:        make(map[reflect.Type][]interface{}, len(info.order)),
+		named:        make(map[string]interface{}, len(info.named)),
+		keyed:        make(map[interface{}]interface{}, len(info.keyed)),
+		groups:       make(map[string][]interface{}, len(info.groups)),
+		factories:    make(map[reflect.Type]*factory, len(info.factories)),
+		decorators:   make(map[reflect.Type][]func(interface{}) interface{}, len(info.decorators)),
+		cleanups:     make(map[interface{}][]func() error, len(info.cleanups)),
+	}
+	for t, deps := range info.dependencies {
+		var set = make(map[interface{}]struct{}, len(deps))
+		for dep := range deps {
+			set[dep] = struct{}{}
+		}
+		c.dependencies[t] = set
+	}
+	for t, deps := range info.order {
+		c.order[t] = append([]interface{}(nil), deps...)
+	}
+	for name, dep := range info.named {
+		c.named[name] = dep
+	}
+	for key, dep := range info.keyed {
+		c.keyed[key] = dep
+	}
+	for group, members := range info.groups {
+		c.groups[group] = append([]interface{}(nil), members...)
+	}
+	for t, f := range info.factories {
+		c.factories[t] = f
+	}
+	for t, fns := range info.decorators {
+		c.decorators[t] = append([]func(interface{}) interface{}(nil), fns...)
+	}
+	for dep, fns := range info.cleanups {
+		c.cleanups[dep] = append([]func() error(nil), fns...)
+	}
+	return c
+}
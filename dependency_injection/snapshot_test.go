@@ -0,0 +1,168 @@
+package dependency_injection
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	di := NewDependencyInjection()
+	base := &testConfig{name: "base"}
+	di.Add(base)
+	restore := di.Snapshot()
+
+	di.Add(&testEnglish{})
+	di.AddNamed("extra", 1)
+	di.Remove(base)
+
+	restore()
+	if _, ok := TryAny[*testEnglish](di); ok {
+		t.Fatal("registration added after the snapshot survived restore")
+	}
+	if _, err := Named[int](di, "extra"); err == nil {
+		t.Fatal("name added after the snapshot survived restore")
+	}
+	if got, ok := TryAny[*testConfig](di); !ok || got != base {
+		t.Fatal("registration removed after the snapshot not restored")
+	}
+
+	di.Add(&testEnglish{})
+	restore()
+	if _, ok := TryAny[*testEnglish](di); ok {
+		t.Fatal("second restore did not discard the new registration")
+	}
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	parent := NewDependencyInjection()
+	source := NewChild(parent)
+	config := &testConfig{name: "source"}
+	source.Add(config)
+	source.AddNamed("port", 8080)
+
+	clone := source.Clone()
+	if got := MustAny[*testConfig](clone); got != config {
+		t.Fatal("clone does not hold the registrations of the source")
+	}
+	if clone.Parent() != parent {
+		t.Fatal("clone does not share the parent of the source")
+	}
+
+	clone.Remove(config)
+	clone.Add(&testEnglish{})
+	clone.AddNamed("port", 9090)
+	if got, ok := TryAny[*testConfig](source); !ok || got != config {
+		t.Fatal("removing from the clone changed the source")
+	}
+	if _, ok := TryAny[*testEnglish](source); ok {
+		t.Fatal("adding to the clone changed the source")
+	}
+	if port := MustNamed[int](source, "port"); port != 8080 {
+		t.Fatalf("renaming in the clone changed the source to %d", port)
+	}
+
+	var debug bytes.Buffer
+	peer := NewDependencyInjection()
+	peer.Add(&testEnglish{accent: "peer"})
+	source = NewDependencyInjection(WithMaxDepth(1), WithInterfaceScan(false))
+	source.SetStrict(true)
+	source.SetRejectZero(true)
+	source.SetMultiPolicy(Error)
+	source.SetDebugWriter(&debug)
+	source.Federate(peer)
+
+	clone = source.Clone()
+	if clone.info.maxDepth != 1 || !clone.info.noInterfaceScan || !clone.info.strict ||
+		!clone.info.rejectZero || clone.info.multi != Error {
+		t.Fatalf("clone settings = %+v, want those of the source", clone.info)
+	}
+	clone.Add(&testConfig{name: "a"})
+	clone.Add(&testConfig{name: "b"})
+	if err := Any(clone, new(*testConfig)); !errors.Is(err, ErrMultipleDependencies) {
+		t.Fatalf("Any of two in a clone of an Error policy container = %v, want ErrMultipleDependencies", err)
+	}
+	if err, _ := panicOf(t, func() { clone.Add(testConfig{}) }).(error); !errors.Is(err, ErrZeroValue) {
+		t.Fatalf("clone Add of a zero value panicked with %v, want ErrZeroValue", err)
+	}
+	if got := MustAny[*testEnglish](clone); got.accent != "peer" {
+		t.Fatalf("clone resolved %v, want the dependency of the federated peer", got)
+	}
+	if !strings.Contains(debug.String(), "add *dependency_injection.testConfig") {
+		t.Fatalf("clone did not log to the debug writer of the source: %q", debug.String())
+	}
+
+	pooled := NewPooledDependencyInjectionSized(NewDependencyInjection(), 1, 2)
+	pc := pooled.Clone()
+	if pc.Lifetime() != Pooled || pc.info.pool == nil || pc.info.pool == pooled.info.pool || pc.info.pool.max != 2 {
+		t.Fatalf("clone of a pooled container has pool %+v, want its own pool of the same size", pc.info.pool)
+	}
+	var made int
+	newer := func(*DependencyInjection) *lifetimeCounter {
+		made++
+		return &lifetimeCounter{n: made}
+	}
+	first := Acquire(pc, newer)
+	Release(pc, first)
+	if second := Acquire(pc, newer); second != first || made != 1 {
+		t.Fatalf("clone of a pooled container made %d objects, want the released one reused", made)
+	}
+}
+
+type mockStore struct {
+	gets int
+}
+
+func (s *mockStore) Get(key string) string {
+	s.gets++
+	return "mock"
+}
+
+func TestWithOverridesReplacesAndRestores(t *testing.T) {
+	di := NewDependencyInjection()
+	real := &testMemoryStore{data: map[string]string{"k": "real"}}
+	AddAs[testStore](di, real)
+	config := &testConfig{name: "real"}
+	di.Add(config)
+
+	mock := &mockStore{}
+	WithOverrides(di, []interface{}{mock, &testConfig{name: "mock"}}, func() {
+		if got := MustAny[testStore](di).Get("k"); got != "mock" {
+			t.Fatalf("testStore inside WithOverrides = %q, want the mock", got)
+		}
+		if got := MustAny[*testConfig](di); got.name != "mock" {
+			t.Fatalf("*testConfig inside WithOverrides = %v, want the override", got)
+		}
+		di.Add(&testEnglish{accent: "added"})
+	})
+
+	if got := MustAny[testStore](di); got != real {
+		t.Fatalf("testStore after WithOverrides = %v, want the real service", got)
+	}
+	if got := MustAny[*testConfig](di); got != config {
+		t.Fatalf("*testConfig after WithOverrides = %v, want the original", got)
+	}
+	if _, ok := TryAny[*mockStore](di); ok {
+		t.Fatal("override still registered after WithOverrides")
+	}
+	if _, ok := TryAny[*testEnglish](di); !ok {
+		t.Fatal("registration made inside WithOverrides was undone")
+	}
+	if mock.gets != 1 {
+		t.Fatalf("mock called %d times, want 1", mock.gets)
+	}
+}
+
+func TestWithOverridesRestoresOnPanic(t *testing.T) {
+	di := NewDependencyInjection()
+	config := &testConfig{name: "real"}
+	di.Add(config)
+
+	panicOf(t, func() {
+		WithOverrides(di, []interface{}{&testConfig{name: "mock"}}, func() { panic("test failed") })
+	})
+	if got := MustAny[*testConfig](di); got != config {
+		t.Fatalf("*testConfig after a panic in WithOverrides = %v, want the original", got)
+	}
+}
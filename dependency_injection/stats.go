@@ -0,0 +1,29 @@
+package dependency_injection
+
+// Stats holds counters describing a container, as returned by Stats().
+type Stats struct {
+	// Registrations is the number of dependencies registered within the container.
+	Registrations int
+	// Hits is the number of Any(...) lookups on the container that found a dependency.
+	Hits uint64
+	// Misses is the number of Any(...) lookups on the container that found nothing.
+	Misses uint64
+	// Keys is the number of distinct type keys registered within the container, see Len().
+	Keys int
+}
+
+// Stats returns the counters of the container. Lookups are counted on the container
+// they were started on, even when a parent provided the dependency.
+func (di *DependencyInjection) Stats() Stats {
+	var stats = Stats{
+		Hits:   di.info.hits.Load(),
+		Misses: di.info.misses.Load(),
+		Keys:   di.Len(),
+	}
+
+	di.info.mutex.RLock()
+	stats.Registrations = len(di.info.registered())
+	di.info.mutex.RUnlock()
+
+	return stats
+}
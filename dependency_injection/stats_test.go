@@ -0,0 +1,37 @@
+package dependency_injection
+
+import "testing"
+
+func TestStatsCounters(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&testConfig{})
+	di.Add(&testConfig{})
+	di.Add(&testEnglish{})
+
+	var config *testConfig
+	var store testStore
+	for i := 0; i < 3; i++ {
+		_ = Any(di, &config)
+	}
+	for i := 0; i < 2; i++ {
+		_ = Any(di, &store)
+	}
+
+	got := di.Stats()
+	if want := (Stats{Registrations: 3, Hits: 3, Misses: 2, Keys: 2}); got != want {
+		t.Fatalf("Stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestStatsCountedOnStartingContainer(t *testing.T) {
+	parent := NewDependencyInjection()
+	parent.Add(&testConfig{})
+	child := NewChild(parent)
+
+	var config *testConfig
+	_ = Any(child, &config)
+	if child.Stats().Hits != 1 || parent.Stats().Hits != 0 {
+		t.Fatalf("child hits %d, parent hits %d; want the lookup counted on the child",
+			child.Stats().Hits, parent.Stats().Hits)
+	}
+}
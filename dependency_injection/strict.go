@@ -0,0 +1,89 @@
+package dependency_injection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrZeroValue is the panic value of registering a nil or zero value within a container
+// after SetRejectZero(true).
+var ErrZeroValue = errors.New("dependency is a zero value")
+
+// ErrSelfRegistration is the panic value of registering a container within itself.
+var ErrSelfRegistration = errors.New("container registered within itself")
+
+// ErrAmbiguous is the panic value of registering, within a strict container, a value
+// of type T while a *T is registered, or the other way around.
+var ErrAmbiguous = errors.New("dependency is ambiguous with a registered one")
+
+// SetStrict sets whether the container rejects registrations that are easily confused
+// with existing ones: in strict mode, registering a value of type T while a *T is
+// registered, or the other way around, panics with ErrAmbiguous. Containers are not
+// strict by default, and both then resolve under their own type.
+func (di *DependencyInjection) SetStrict(strict bool) {
+	di.info.mutex.Lock()
+	di.info.strict = strict
+	di.info.mutex.Unlock()
+}
+
+// SetRejectZero sets whether the container rejects zero values, which usually stand for
+// a construction that failed, such as a nil *sql.DB: registering nil, a nil pointer or
+// any other zero value, such as an empty struct, panics with ErrZeroValue, and resolution
+// skips zero values registered before. Zero values are accepted by default.
+func (di *DependencyInjection) SetRejectZero(reject bool) {
+	di.info.mutex.Lock()
+	di.info.rejectZero = reject
+	di.info.publish()
+	di.info.mutex.Unlock()
+}
+
+// admit checks that deps may be registered in order: none is the container itself, and
+// as set with SetRejectZero(...) and SetStrict(...). It must be called with the write lock
+// held; a rejected registration releases the lock and panics with ErrSelfRegistration,
+// ErrZeroValue or ErrAmbiguous.
+func (info *dependencyInjection) admit(deps ...interface{}) {
+	for _, dep := range deps {
+		if c, ok := dep.(*DependencyInjection); ok && c != nil && c.info == info {
+			info.mutex.Unlock()
+			panic(ErrSelfRegistration)
+		}
+	}
+	if info.rejectZero {
+		for _, dep := range deps {
+			if zero(dep) {
+				info.mutex.Unlock()
+				panic(fmt.Errorf("%w: %v", ErrZeroValue, typeKey(dep)))
+			}
+		}
+	}
+	if !info.strict {
+		return
+	}
+	var types = make(map[reflect.Type]struct{})
+	for _, dep := range info.registered() {
+		types[typeKey(dep)] = struct{}{}
+	}
+	for _, dep := range deps {
+		var t = typeKey(dep)
+		if t == nil {
+			continue
+		}
+		var other = reflect.PointerTo(t)
+		if t.Kind() == reflect.Pointer {
+			if _, ok := types[t.Elem()]; ok {
+				other = t.Elem()
+			}
+		}
+		if _, ok := types[other]; ok {
+			info.mutex.Unlock()
+			panic(fmt.Errorf("%w: %v and %v", ErrAmbiguous, t, other))
+		}
+		types[t] = struct{}{}
+	}
+}
+
+// zero reports whether dep is nil or the zero value of its type.
+func zero(dep interface{}) bool {
+	return dep == nil || reflect.ValueOf(dep).IsZero()
+}
@@ -0,0 +1,97 @@
+package dependency_injection
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStrictRejectsValueAndPointer(t *testing.T) {
+	di := NewDependencyInjection()
+	di.SetStrict(true)
+	di.Add(testConfig{name: "value"})
+
+	err, _ := panicOf(t, func() { di.Add(&testConfig{name: "pointer"}) }).(error)
+	if !errors.Is(err, ErrAmbiguous) {
+		t.Fatalf("Add of *testConfig next to testConfig panicked with %v, want ErrAmbiguous", err)
+	}
+	if _, ok := TryAny[*testConfig](di); ok {
+		t.Fatal("rejected registration is resolvable")
+	}
+
+	di.Add(&testEnglish{accent: "uk"})
+	err, _ = panicOf(t, func() { di.AddAll(&lifetimeCounter{}, testEnglish{}) }).(error)
+	if !errors.Is(err, ErrAmbiguous) {
+		t.Fatalf("AddAll of testEnglish next to *testEnglish panicked with %v, want ErrAmbiguous", err)
+	}
+	if _, ok := TryAny[*lifetimeCounter](di); ok {
+		t.Fatal("AddAll registered part of an ambiguous batch")
+	}
+
+	di.Add(testConfig{name: "again"})
+	if got := MustAny[testConfig](di); got.name != "again" {
+		t.Fatalf("strict container rejected adding the same type again: %v", got)
+	}
+}
+
+func TestPermissiveAllowsValueAndPointer(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(testConfig{name: "value"})
+	di.Add(&testConfig{name: "pointer"})
+
+	if got := MustAny[testConfig](di); got.name != "value" {
+		t.Fatalf("testConfig = %v, want the value", got)
+	}
+	if got := MustAny[*testConfig](di); got.name != "pointer" {
+		t.Fatalf("*testConfig = %v, want the pointer", got)
+	}
+}
+
+func TestRejectZeroNilPointer(t *testing.T) {
+	di := NewDependencyInjection()
+	di.SetRejectZero(true)
+
+	var config *testConfig
+	err, _ := panicOf(t, func() { di.Add(config) }).(error)
+	if !errors.Is(err, ErrZeroValue) {
+		t.Fatalf("Add of a nil pointer panicked with %v, want ErrZeroValue", err)
+	}
+	err, _ = panicOf(t, func() { di.AddWithCleanup(config, func() error { return nil }) }).(error)
+	if !errors.Is(err, ErrZeroValue) {
+		t.Fatalf("AddWithCleanup of a nil pointer panicked with %v, want ErrZeroValue", err)
+	}
+	if _, ok := TryAny[*testConfig](di); ok {
+		t.Fatal("rejected nil pointer is resolvable")
+	}
+}
+
+func TestRejectZeroNilInterface(t *testing.T) {
+	di := NewDependencyInjection()
+	di.SetRejectZero(true)
+
+	var store testStore
+	err, _ := panicOf(t, func() { AddAs[testStore](di, store) }).(error)
+	if !errors.Is(err, ErrZeroValue) {
+		t.Fatalf("AddAs of a nil interface panicked with %v, want ErrZeroValue", err)
+	}
+	err, _ = panicOf(t, func() { di.AddAll(&testConfig{}, nil) }).(error)
+	if !errors.Is(err, ErrZeroValue) {
+		t.Fatalf("AddAll with nil panicked with %v, want ErrZeroValue", err)
+	}
+	if _, ok := TryAny[*testConfig](di); ok {
+		t.Fatal("AddAll registered part of a batch holding nil")
+	}
+}
+
+func TestRejectZeroSkipsRegistered(t *testing.T) {
+	di := NewDependencyInjection()
+	var config *testConfig
+	di.Add(config)
+	if got, ok := TryAny[*testConfig](di); !ok || got != nil {
+		t.Fatalf("nil pointer not resolvable by default: %v, %v", got, ok)
+	}
+
+	di.SetRejectZero(true)
+	if _, ok := TryAny[*testConfig](di); ok {
+		t.Fatal("resolution returned a zero value after SetRejectZero(true)")
+	}
+}
@@ -0,0 +1,132 @@
+package dependency_injection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Validate checks, without invoking anything, that every parameter of the constructors
+// registered with AddConstructor(...) in the container and its parents can be resolved
+// from the container, and that no constructors depend on each other in a cycle. The
+// returned error joins an ErrDependencyNotFound for each missing link and an
+// ErrCircularDependency for each cycle. Factories added with AddFactory(...) and
+// similar are opaque and assumed to be valid.
+func (di *DependencyInjection) Validate() error {
+	var types []reflect.Type
+	var factories = make(map[reflect.Type]*factory)
+	var visited visitedSet
+	for c := di; c != nil && visited.add(c.info); c = c.Parent() {
+		c.info.mutex.RLock()
+		for t, f := range c.info.factories {
+			if _, ok := factories[t]; !ok {
+				factories[t] = f
+				types = append(types, t)
+			}
+		}
+		c.info.mutex.RUnlock()
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return typeName(types[i]) < typeName(types[j])
+	})
+
+	var errs []error
+	for _, t := range types {
+		for _, p := range factories[t].params {
+			if p == reflect.TypeOf(di) || p.Kind() == reflect.Slice || factories[p] != nil {
+				continue
+			}
+			if !di.resolvable(p) {
+				errs = append(errs, fmt.Errorf("%w: %s required by %s", ErrDependencyNotFound, p, t))
+			}
+		}
+	}
+
+	// Walk the constructors depth first; reaching a type that is still on the
+	// stack closes a cycle.
+	var state = make(map[reflect.Type]int)
+	var stack []reflect.Type
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		state[t] = 1
+		stack = append(stack, t)
+		for _, p := range factories[t].params {
+			if factories[p] == nil {
+				continue
+			}
+			if _, ok := di.provided(p); ok {
+				continue
+			}
+			switch state[p] {
+			case 0:
+				walk(p)
+			case 1:
+				var names []string
+				for i := len(stack) - 1; i >= 0; i-- {
+					names = append(names, stack[i].String())
+					if stack[i] == p {
+						break
+					}
+				}
+				for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+					names[i], names[j] = names[j], names[i]
+				}
+				names = append(names, p.String())
+				errs = append(errs, fmt.Errorf("%w: %s", ErrCircularDependency, strings.Join(names, " -> ")))
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[t] = 2
+	}
+	for _, t := range types {
+		if state[t] == 0 {
+			walk(t)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RequireAll checks that a dependency of each of the given types is registered within
+// the container, its parents or the containers they federate, or has a factory there,
+// without invoking anything. Each
+// type is given by a value of it, or by its reflect.Type, which is how interfaces are
+// required, e.g. reflect.TypeOf((*io.Reader)(nil)).Elem(). The returned error joins a
+// DependencyNotFoundError for each missing type.
+func (di *DependencyInjection) RequireAll(types ...interface{}) error {
+	var errs []error
+	for _, token := range types {
+		t, ok := token.(reflect.Type)
+		if !ok {
+			t = reflect.TypeOf(token)
+		}
+		if t == nil {
+			errs = append(errs, fmt.Errorf("%w: nil, pass the reflect.Type of an interface", ErrDependencyNotFound))
+			continue
+		}
+		if !di.resolvable(t) {
+			errs = append(errs, &DependencyNotFoundError{Type: t.String()})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resolvable reports whether a dependency of type key t is registered within the
+// container, its parents or the containers they federate, or has a factory there, like Has(...).
+func (di *DependencyInjection) resolvable(t reflect.Type) bool {
+	return !di.walk(func(c *DependencyInjection, _ int, _ bool) bool {
+		_, ok := c.find(t, isType(t))
+		return !ok && c.factoryOf(t) == nil
+	})
+}
+
+// provided returns a dependency of type key t already registered within the container,
+// its parents or the containers they federate, without invoking factories or decorators.
+func (di *DependencyInjection) provided(t reflect.Type) (dep interface{}, ok bool) {
+	di.walk(func(c *DependencyInjection, _ int, _ bool) bool {
+		dep, ok = c.find(t, isType(t))
+		return !ok
+	})
+	return
+}
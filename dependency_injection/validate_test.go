@@ -0,0 +1,49 @@
+package dependency_injection
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRequireAllPartial(t *testing.T) {
+	parent := NewDependencyInjection()
+	parent.Add(&testConfig{name: "app"})
+	di := NewChild(parent)
+	di.Add(&testMemoryStore{})
+	AddFactory(di, func(*DependencyInjection) *testPerson { return &testPerson{name: "ann"} })
+
+	err := di.RequireAll(
+		&testConfig{},
+		reflect.TypeOf((*testStore)(nil)).Elem(),
+		&testPerson{},
+		testEnglish{},
+		reflect.TypeOf((*testNamer)(nil)).Elem(),
+	)
+	if !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("RequireAll = %v, want ErrDependencyNotFound", err)
+	}
+	var msg = err.Error()
+	for _, missing := range []string{"testEnglish", "testNamer"} {
+		if !strings.Contains(msg, missing) {
+			t.Fatalf("RequireAll = %q, want it to name %s", msg, missing)
+		}
+	}
+	for _, present := range []string{"testConfig", "testStore", "testPerson"} {
+		if strings.Contains(msg, present) {
+			t.Fatalf("RequireAll = %q, which names %s although it is present", msg, present)
+		}
+	}
+}
+
+func TestRequireAllPresent(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&testConfig{})
+	if err := di.RequireAll(&testConfig{}); err != nil {
+		t.Fatalf("RequireAll = %v, want nil", err)
+	}
+	if err := di.RequireAll(nil); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("RequireAll(nil) = %v, want ErrDependencyNotFound", err)
+	}
+}
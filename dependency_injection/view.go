@@ -0,0 +1,81 @@
+package dependency_injection
+
+import (
+	"reflect"
+)
+
+// view is an immutable copy of what resolution reads from a frozen container. It is
+// published atomically whenever the frozen container changes, e.g. by caching an object
+// made by a factory, so that resolving from a frozen container takes no lock. The maps
+// are copied and the slices they hold shared: the container only appends to a slice or
+// replaces it, which never changes the elements a published view sees.
+type view struct {
+	order           map[reflect.Type][]interface{}
+	implementers    map[reflect.Type][]interface{}
+	factories       map[reflect.Type]*factory
+	decorators      map[reflect.Type][]func(interface{}) interface{}
+	middlewares     []func(next ResolveFunc) ResolveFunc
+	hooks           []func(typeName string, found bool)
+	federated       []*DependencyInjection
+	noInterfaceScan bool
+	rejectZero      bool
+	multi           MultiPolicy
+}
+
+// publish publishes a new view of a frozen container, after a change to what resolution
+// reads from it. It must be called with the write lock held, and does nothing for a
+// container that is not frozen.
+func (info *dependencyInjection) publish() {
+	if !info.frozen {
+		return
+	}
+	var v = &view{
+		order:           make(map[reflect.Type][]interface{}, len(info.order)),
+		implementers:    make(map[reflect.Type][]interface{}, len(info.implementers)),
+		factories:       make(map[reflect.Type]*factory, len(info.factories)),
+		decorators:      make(map[reflect.Type][]func(interface{}) interface{}, len(info.decorators)),
+		middlewares:     info.middlewares,
+		hooks:           info.hooks,
+		federated:       info.federated,
+		noInterfaceScan: info.noInterfaceScan,
+		rejectZero:      info.rejectZero,
+		multi:           info.multi,
+	}
+	for t, deps := range info.order {
+		v.order[t] = deps
+	}
+	for t, deps := range info.implementers {
+		v.implementers[t] = deps
+	}
+	for t, f := range info.factories {
+		v.factories[t] = f
+	}
+	for t, fns := range info.decorators {
+		v.decorators[t] = fns
+	}
+	info.view.Store(v)
+}
+
+// find resolves a dependency satisfying match like DependencyInjection.find, reporting
+// with done whether the view could tell: an interface that is not indexed yet has to be
+// resolved from the container, which indexes it.
+func (v *view) find(t0 reflect.Type, match func(dep interface{}) bool) (dep interface{}, ok, done bool) {
+	if v.rejectZero {
+		var accept = match
+		match = func(dep interface{}) bool {
+			return accept(dep) && !zero(dep)
+		}
+	}
+
+	var deps1 []interface{}
+	if !v.noInterfaceScan && t0.Kind() == reflect.Interface {
+		var indexed bool
+		if deps1, indexed = v.implementers[t0]; !indexed {
+			return nil, false, false
+		}
+	} else if !v.noInterfaceScan {
+		deps1 = v.order[globalKey]
+	}
+	dep, ok = pick(v.multi, match, v.order[t0], deps1)
+	return dep, ok, true
+}
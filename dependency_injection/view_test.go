@@ -0,0 +1,72 @@
+package dependency_injection
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestFrozenViewFollowsCaching(t *testing.T) {
+	di := NewDependencyInjection()
+	config := &testConfig{name: "app"}
+	di.Add(config)
+	AddFactory(di, func(*DependencyInjection) *testPerson { return &testPerson{name: "ann"} })
+	di.Freeze()
+
+	if got := MustAny[*testConfig](di); got != config {
+		t.Fatalf("frozen container resolved %v, want the registration", got)
+	}
+	person := MustAny[*testPerson](di)
+	if got := MustAny[*testPerson](di); got != person {
+		t.Fatal("frozen container made its factory object twice")
+	}
+	if got := MustAny[testNamer](di); got != person {
+		t.Fatal("frozen container did not resolve the cached object through its interface")
+	}
+	made := MustNeed(di, func(*DependencyInjection) *testEnglish { return &testEnglish{accent: "uk"} })
+	if got, ok := TryAny[testEnglish](di); !ok || got != made {
+		t.Fatalf("frozen container did not publish the object cached by MustNeed: %v, %v", got, ok)
+	}
+}
+
+func TestFrozenViewFollowsSettings(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&testPerson{name: "ann"})
+	di.Freeze()
+
+	var found []bool
+	di.OnResolve(func(typeName string, ok bool) { found = append(found, ok) })
+	Decorate(di, func(inner *testPerson) *testPerson { return &testPerson{name: "decorated " + inner.name} })
+	if got := MustAny[*testPerson](di); got.name != "decorated ann" {
+		t.Fatalf("frozen container ignored a decorator added after Freeze: %v", got)
+	}
+	if len(found) != 1 || !found[0] {
+		t.Fatalf("frozen container ignored a hook added after Freeze: %v", found)
+	}
+
+	di.EnableInterfaceScan(false)
+	if _, ok := TryAny[testNamer](di); ok {
+		t.Fatal("frozen container scanned interfaces after EnableInterfaceScan(false)")
+	}
+}
+
+func TestFrozenViewConcurrent(t *testing.T) {
+	di := NewDependencyInjection()
+	di.Add(&testPerson{name: "ann"})
+	di.Freeze()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				MustAny[testNamer](di)
+				MustNeed(di, func(*DependencyInjection) *lifetimeCounter { return &lifetimeCounter{n: i} })
+			}
+		}(i)
+	}
+	wg.Wait()
+	if got := All[lifetimeCounter](di); len(got) != 1 {
+		t.Fatalf("MustNeed on a frozen container cached %d objects, want 1", len(got))
+	}
+}